@@ -1,74 +1,119 @@
 package main
 
 import (
-	"github.com/PuerkitoBio/goquery"
-	"net/http"
+	"fmt"
+	"isbnAPI/isbn"
+	"isbnAPI/metadata"
+	"sort"
 	"strings"
 )
 
-func IsValidISBN10(isbn string) bool {
-	if len(isbn) != 10 {
-		return false
+// IsValidISBN10 reports whether s is an ISBN-10 without separators.
+// Use isbn.Validate10 to learn which rule failed.
+func IsValidISBN10(s string) bool {
+	return isbn.Validate10(s) == nil
+}
+
+// IsValidISBN13 reports whether s is an ISBN-13 without separators.
+// Use isbn.Validate13 to learn which rule failed.
+func IsValidISBN13(s string) bool {
+	return isbn.Validate13(s) == nil
+}
+
+// NormalizeISBN returns the ISBN-13 form of an ISBN so the ISBN-10 and
+// ISBN-13 of an edition share a cache entry. Strings that do not parse only
+// have their separators stripped.
+func NormalizeISBN(s string) string {
+	if i, err := isbn.Parse(s); err == nil {
+		return i.To13().String()
 	}
-	sum := 0
-	for i := 0; i < 10; i++ {
-		if isbn[i] == 'X' {
-			sum += 10 * (10 - i)
-		} else {
-			sum += int(isbn[i]-'0') * (10 - i)
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.NewReplacer("-", "", " ", "").Replace(s)
+}
+
+// validISBNs returns the canonical form of the valid ISBNs in list and drops the others
+func validISBNs(list []string) []string {
+	valid := make([]string, 0, len(list))
+	for _, s := range list {
+		i, err := isbn.Parse(s)
+		if err != nil {
+			fmt.Printf("Skipping invalid ISBN %q: %v\n", s, err)
+			continue
 		}
+		valid = append(valid, i.String())
 	}
-	return sum%11 == 0
+	return valid
 }
 
-func IsValidISBN13(isbn string) bool {
-	if len(isbn) != 13 {
-		return false
+// printRank orders editions for pricing: print editions first, then editions
+// of unknown form, then ebooks, audiobooks and the like.
+func printRank(form string) int {
+	form = strings.ToLower(form)
+	for _, word := range []string{"paperback", "pbk", "hardcover", "hardback", "hbk", "cloth", "print", "trade", "library binding", "board book", "mass market"} {
+		if strings.Contains(form, word) {
+			return 0
+		}
 	}
-	sum := 0
-	for i := 0; i < 13; i++ {
-		if i%2 == 0 {
-			sum += int(isbn[i] - '0')
-		} else {
-			sum += 3 * int(isbn[i]-'0')
+	for _, word := range []string{"ebook", "e-book", "electronic", "online", "audio", "compact disc", "kindle", "epub", "pdf", "digital"} {
+		if strings.Contains(form, word) {
+			return 2
 		}
 	}
-	return sum%10 == 0
+	return 1
 }
 
-func ISBNContent(isbn string) map[string]string {
-	resp, err := http.Get("https://isbndb.com/book/" + isbn)
-	if err != nil {
-		return nil
+// priceRecord tries the ISBNs of a record, print editions first, until one
+// has a list price. A print edition with a price is taken as soon as it is
+// found; otherwise the first priced edition is used. It reports false when no
+// ISBN has a price.
+func priceRecord(isbns []string, qualifiers map[string]string) (metadata.BookMetadata, bool) {
+	ordered := make([]string, len(isbns))
+	copy(ordered, isbns)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return printRank(qualifiers[ordered[i]]) < printRank(qualifiers[ordered[j]])
+	})
+
+	var fallback *metadata.BookMetadata
+	for _, s := range ordered {
+		m, err := ISBNContent(s)
+		if err != nil || m.ListPrice == nil {
+			continue
+		}
+		form := qualifiers[s]
+		if form == "" {
+			form = m.Binding
+		}
+		if printRank(form) == 0 {
+			return m, true
+		}
+		if fallback == nil {
+			fallback = &m
+		}
+	}
+	if fallback == nil {
+		return metadata.BookMetadata{}, false
 	}
-	defer resp.Body.Close()
+	return *fallback, true
+}
 
-	// Parse the page
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+// ISBNContent asks the configured metadata providers about the ISBN, using
+// the ISBN cache when it is enabled. The error is metadata.ErrNotFound when no
+// provider knows the ISBN.
+func ISBNContent(isbn string) (metadata.BookMetadata, error) {
+	key := NormalizeISBN(isbn)
+	cacheKey := "metadata/v2:" + key
+	m := metadata.BookMetadata{}
+	if isbnCache != nil && isbnCache.Get(cacheKey, &m) {
+		return m, nil
+	}
+	found, err := metadataProvider.Lookup(key)
 	if err != nil {
-		return nil
+		return m, err
 	}
-	// Find the price
-	content := make(map[string]string)
-	doc.Find(".book-table .table").Each(func(i int, bookTable *goquery.Selection) {
-		bookTable.Find("tr").Each(func(i int, tr *goquery.Selection) {
-			val := ""
-			des := tr.Find("th:nth-child(1)").Text()
-			desp := strings.ToLower(strings.TrimSpace(des))
-			switch desp {
-			case "isbn":
-				val = tr.Find("th:nth-child(2)").Text()
-			case "related isbns":
-				relatedISBNs := make([]string, 0)
-				tr.Find("a").Each(func(i int, s *goquery.Selection) {
-					relatedISBNs = append(relatedISBNs, s.Text())
-				})
-				val = strings.Join(relatedISBNs, ",")
-			default:
-				val = tr.Find("td").Text()
-			}
-			content[desp] = strings.TrimSpace(val)
-		})
-	})
-	return content
+	if isbnCache != nil {
+		if err := isbnCache.Put(cacheKey, found); err != nil {
+			fmt.Println("Error caching ISBN metadata:", err)
+		}
+	}
+	return *found, nil
 }
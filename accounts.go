@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"isbnAPI/aspen"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Account is a linked library account with its own session
+type Account struct {
+	AccountConfig
+
+	client *aspen.Client
+	// syncMu serializes the reading history syncs of the account
+	syncMu sync.Mutex
+
+	// mu guards the connection state below, which is updated by connectLoop
+	mu        sync.RWMutex
+	connected bool
+	lastErr   error
+}
+
+const (
+	// minRetryDelay and maxRetryDelay bound the backoff between login attempts
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = 10 * time.Minute
+)
+
+var (
+	// accounts holds the linked accounts by id
+	accounts = make(map[string]*Account)
+	// accountIDs keeps the configured order. The first id is the default account
+	accountIDs []string
+)
+
+// newAccount creates an account with a client for the configured library
+func newAccount(config Config, ac AccountConfig) *Account {
+	c := aspen.NewClient(config.BaseURL, ac.PatronID, time.Duration(RequestTimeout)*time.Second)
+	c.CookieName = config.CookieName
+	return &Account{
+		AccountConfig: ac,
+		client:        c,
+	}
+}
+
+// connect logs the account in
+func (a *Account) connect() error {
+	if !login(a.client, a.Username, a.Password) {
+		return fmt.Errorf("login failed for account %s", a.ID)
+	}
+	a.mu.Lock()
+	a.connected = true
+	a.lastErr = nil
+	a.mu.Unlock()
+	return nil
+}
+
+// connectLoop keeps trying to connect the account, doubling the delay between
+// attempts, until it succeeds. It is meant to run in its own goroutine so the
+// server can start while the library site is down.
+func (a *Account) connectLoop() {
+	delay := minRetryDelay
+	for {
+		err := a.connect()
+		if err == nil {
+			fmt.Printf("Login successfully: %s\n", a.ID)
+			return
+		}
+		a.mu.Lock()
+		a.lastErr = err
+		a.mu.Unlock()
+		fmt.Printf("Error connecting account %s, retrying in %v: %v\n", a.ID, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// status describes the connection state of the account for the /accounts/ endpoint
+func (a *Account) status() map[string]interface{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	status := map[string]interface{}{
+		"id":        a.ID,
+		"connected": a.connected,
+	}
+	if a.lastErr != nil {
+		status["error"] = a.lastErr.Error()
+	}
+	return status
+}
+
+// accountFor returns the account named by the ?account= query parameter, or
+// the default account when it is absent. It writes a 404 and returns nil when
+// the account is unknown, and a 503 when it is not logged in yet.
+func accountFor(w http.ResponseWriter, r *http.Request) *Account {
+	id := r.URL.Query().Get("account")
+	if id == "" {
+		id = accountIDs[0]
+	}
+	a, ok := accounts[id]
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "unknown account "+id)
+		return nil
+	}
+	a.mu.RLock()
+	connected, lastErr := a.connected, a.lastErr
+	a.mu.RUnlock()
+	if !connected {
+		message := "account " + id + " is not logged in to the library yet"
+		if lastErr != nil {
+			message += ": " + lastErr.Error()
+		}
+		w.Header().Set("Retry-After", fmt.Sprint(int(minRetryDelay.Seconds())))
+		writeJSONError(w, http.StatusServiceUnavailable, message)
+		return nil
+	}
+	return a
+}
+
+// writeJSONError writes a JSON error body with the given status code
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error":   http.StatusText(status),
+		"message": message,
+	})
+}
+
+// writeJSON writes v as indented JSON with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	resJson, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(resJson)
+}
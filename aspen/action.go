@@ -0,0 +1,67 @@
+package aspen
+
+import (
+	"encoding/json"
+	"github.com/PuerkitoBio/goquery"
+	"net/url"
+	"strings"
+)
+
+// actionResult is the answer of an AJAX action such as a renewal
+type actionResult struct {
+	Success bool
+	// Message is the library's message as plain text
+	Message string
+}
+
+// action calls an AJAX method of an Aspen module, e.g. "MyAccount" and
+// "renewCheckout", with the form values as arguments
+func (c *Client) action(module, method string, form url.Values) (actionResult, error) {
+	body, err := c.postAccountAction(c.BaseURL+"/"+module+"/AJAX?method="+url.QueryEscape(method), form)
+	if err != nil {
+		return actionResult{}, err
+	}
+
+	// Aspen puts the message in "message", "body" or "modalBody" depending on
+	// the method, and some methods send a list of messages
+	var respJson struct {
+		Success   Flag            `json:"success"`
+		Title     string          `json:"title"`
+		Message   json.RawMessage `json:"message"`
+		Body      string          `json:"body"`
+		ModalBody string          `json:"modalBody"`
+	}
+	if err := json.Unmarshal(body, &respJson); err != nil {
+		return actionResult{}, err
+	}
+	result := actionResult{Success: bool(respJson.Success)}
+	for _, message := range []string{messageText(respJson.Message), respJson.Body, respJson.ModalBody, respJson.Title} {
+		if text := plainText(message); text != "" {
+			result.Message = text
+			break
+		}
+	}
+	return result, nil
+}
+
+// messageText reads a message sent as a string or as a list of strings
+func messageText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return strings.Join(list, " ")
+	}
+	return ""
+}
+
+// plainText strips the markup of an HTML fragment and collapses its spaces
+func plainText(html string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return strings.TrimSpace(html)
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
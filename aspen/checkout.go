@@ -0,0 +1,160 @@
+package aspen
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Checkout is a title currently checked out by the patron
+type Checkout struct {
+	// ID identifies the checkout. Two copies or formats of the same title
+	// have different ids.
+	ID       string `json:"id"`
+	RecordId string `json:"recordId"`
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	Format   string `json:"format"`
+	// Source is "ils" for the library's own copies, otherwise the lending
+	// service, e.g. "overdrive" or "hoopla"
+	Source   string    `json:"source"`
+	Barcode  string    `json:"barcode"`
+	DueDate  Timestamp `json:"dueDate"`
+	Renewals int       `json:"renewals"`
+	// RenewalsRemaining is -1 when the library does not show it
+	RenewalsRemaining int  `json:"renewalsRemaining"`
+	Renewable         bool `json:"renewable"`
+	Overdue           bool `json:"overdue"`
+
+	// patronId and renewIndicator are the arguments Aspen expects to renew
+	// the checkout, and renewModule the AJAX module handling the renewal,
+	// e.g. "Account" or "OverDrive"
+	patronId       string
+	renewIndicator string
+	renewModule    string
+}
+
+var (
+	// checkoutClassRe matches the class naming a checkout row, e.g.
+	// "ilsCheckout_ils_12345" or "overdrive_checkout_6f1c..."
+	checkoutClassRe = regexp.MustCompile(`^(?:ilsCheckout_([A-Za-z0-9]+)_(.+)|(.+?)_checkout_(.+))$`)
+	// renewCallRe matches the call made by a renew button, e.g.
+	// "AspenDiscovery.Account.renewTitle('42', '12345', '67890')"
+	renewCallRe = regexp.MustCompile(`(?i)(?:AspenDiscovery\.(\w+)\.)?renew\w*\(([^)]*)\)`)
+	// selectedRe matches the name of the checkbox selecting a checkout,
+	// "selected[patronId|recordId|renewIndicator]"
+	selectedRe = regexp.MustCompile(`^selected\[([^|\]]*)\|([^|\]]*)\|([^\]]*)]$`)
+	// remainingRe and ofRe read the renewals left from "1 (2 remaining)" or "1 of 3"
+	remainingRe = regexp.MustCompile(`(?i)(\d+)\s+remaining`)
+	ofRe        = regexp.MustCompile(`(?i)(\d+)\s+of\s+(\d+)`)
+)
+
+// Checkouts returns the titles currently checked out by the patron, from the
+// library and from every lending service linked to the account.
+func (c *Client) Checkouts() ([]Checkout, error) {
+	rows, err := c.accountResults("getCheckouts", "checkouts")
+	if err != nil {
+		return nil, err
+	}
+
+	var checkouts []Checkout
+	rows.Each(func(i int, s *goquery.Selection) {
+		checkouts = append(checkouts, parseCheckout(s))
+	})
+	return checkouts, nil
+}
+
+// parseCheckout reads a checkout row of the checkouts page
+func parseCheckout(s *goquery.Selection) Checkout {
+	checkout := Checkout{
+		Title:             strings.TrimSpace(s.Find(".result-title").First().Text()),
+		RenewalsRemaining: -1,
+	}
+
+	values := resultValues(s)
+	checkout.Author = values["author"]
+	checkout.Format = values["format"]
+	checkout.Barcode = values["barcode"]
+
+	// Lending services show when the loan expires rather than a due date
+	due := firstValue(values, "due", "due date", "expires")
+	checkout.DueDate = dateIn(due)
+
+	for key, value := range values {
+		if strings.HasPrefix(key, "renew") {
+			checkout.readRenewals(value)
+		}
+	}
+
+	checkout.Source, checkout.RecordId = resultSource(s, checkoutClassRe)
+
+	// The selection checkbox carries the renew arguments even when the
+	// title cannot be renewed
+	if match := selectedRe.FindStringSubmatch(s.Find("input.titleSelect").AttrOr("name", "")); match != nil {
+		checkout.patronId, checkout.renewIndicator = match[1], match[3]
+		if checkout.RecordId == "" {
+			checkout.RecordId = match[2]
+		}
+	}
+	s.Find("[onclick]").EachWithBreak(func(i int, button *goquery.Selection) bool {
+		match := renewCallRe.FindStringSubmatch(button.AttrOr("onclick", ""))
+		if match == nil {
+			return true
+		}
+		checkout.Renewable = true
+		checkout.renewModule = match[1]
+		args := callArgs(match[2])
+		if len(args) > 0 && checkout.patronId == "" {
+			checkout.patronId = args[0]
+		}
+		if len(args) > 1 && checkout.RecordId == "" {
+			checkout.RecordId = args[1]
+		}
+		if len(args) > 2 && checkout.renewIndicator == "" {
+			checkout.renewIndicator = args[2]
+		}
+		return false
+	})
+	if checkout.RenewalsRemaining == 0 {
+		checkout.Renewable = false
+	}
+
+	overdueLabel := strings.Contains(strings.ToLower(due), "overdue")
+	// Due dates carry no time of day, so compare with the start of today
+	y, m, d := time.Now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	checkout.Overdue = overdueLabel || (!checkout.DueDate.IsZero() && checkout.DueDate.Before(today))
+
+	checkout.ID = checkoutID(checkout)
+	return checkout
+}
+
+// readRenewals reads the renewal count, e.g. "2 times", "1 of 3" or "1 (2 remaining)"
+func (c *Checkout) readRenewals(value string) {
+	if match := ofRe.FindStringSubmatch(value); match != nil {
+		used, _ := strconv.Atoi(match[1])
+		max, _ := strconv.Atoi(match[2])
+		c.Renewals, c.RenewalsRemaining = used, max-used
+		return
+	}
+	if n, err := strconv.Atoi(strings.Fields(value + " x")[0]); err == nil {
+		c.Renewals = n
+	}
+	if match := remainingRe.FindStringSubmatch(value); match != nil {
+		c.RenewalsRemaining, _ = strconv.Atoi(match[1])
+	}
+}
+
+// checkoutID joins the source, the record and the copy of a checkout, e.g.
+// "ils-12345-67890"
+func checkoutID(c Checkout) string {
+	parts := []string{c.Source, c.RecordId}
+	if c.renewIndicator != "" && c.renewIndicator != c.RecordId {
+		parts = append(parts, c.renewIndicator)
+	} else if c.Barcode != "" {
+		parts = append(parts, c.Barcode)
+	}
+	return joinID(parts...)
+}
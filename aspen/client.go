@@ -0,0 +1,250 @@
+// Package aspen is a small client for the patron-facing AJAX endpoints of an
+// Aspen Discovery library catalog.
+package aspen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client talks to an Aspen Discovery instance on behalf of a single patron.
+type Client struct {
+	// BaseURL is the root of the catalog, e.g. https://discovery.roundrocktexas.gov
+	BaseURL string
+	// PatronID is the patron id used by the MyAccount AJAX methods
+	PatronID string
+	// CookieName is the name of the session cookie set by the catalog
+	CookieName string
+	// HTTPClient performs the requests. It shares Jar so the session survives between calls
+	HTTPClient *http.Client
+	// Jar stores the session cookie set by Login
+	Jar http.CookieJar
+
+	// mu guards the credentials and session counter used to log in again,
+	// and the patron information returned by the latest login
+	mu       sync.Mutex
+	username string
+	password string
+	session  int
+	result   Result
+}
+
+// Result is the patron information returned by a successful login
+type Result struct {
+	Success                bool   `json:"success"`
+	TwoFactor              bool   `json:"twoFactor"`
+	Name                   string `json:"name"`
+	Phone                  string `json:"phone"`
+	Email                  string `json:"email"`
+	HomeLocation           string `json:"homeLocation"`
+	HomeLocationId         string `json:"homeLocationId"`
+	EnableMaterialsRequest bool   `json:"enableMaterialsRequest"`
+}
+
+type loginResp struct {
+	Result Result `json:"result"`
+}
+
+// Book is a title in the patron's reading history
+type Book struct {
+	Author      string `json:"author"`
+	Title       string `json:"title"`
+	Format      string `json:"format"`
+	LinkUrl     string `json:"linkUrl"`
+	PermanentId string `json:"permanentId"`
+	ISBN        string `json:"isbn"`
+	ListPrice   string `json:"list price"`
+	// Checkout is when the title was checked out
+	Checkout Timestamp `json:"checkout"`
+	// Checkin is when the title was returned, zero while it is checked out
+	Checkin Timestamp `json:"checkin"`
+	// LastCheckout is the latest checkout when the title was borrowed several times
+	LastCheckout Timestamp `json:"lastCheckout"`
+	// CheckedOut is set while the title is still checked out
+	CheckedOut Flag `json:"checkedOut"`
+	// TimesUsed counts the checkouts of the title
+	TimesUsed Count `json:"timesUsed"`
+	// Source is the system the title came from, e.g. "ils" or "overdrive"
+	Source   string `json:"source"`
+	RecordId ID     `json:"recordId"`
+	CoverUrl string `json:"coverUrl"`
+	// ExistsInCatalog is false for titles that were removed from the catalog
+	ExistsInCatalog Flag `json:"existsInCatalog"`
+}
+
+// LastCheckedOut returns the latest known checkout date of the title
+func (b Book) LastCheckedOut() time.Time {
+	if b.LastCheckout.After(b.Checkout.Time) {
+		return b.LastCheckout.Time
+	}
+	return b.Checkout.Time
+}
+
+// History is one page of the reading history
+type History struct {
+	Success bool   `json:"success"`
+	Titles  []Book `json:"titles"`
+}
+
+// DefaultCookieName is the session cookie used by a stock Aspen Discovery install
+const DefaultCookieName = "aspen_session"
+
+// ErrLoginFailed is returned by Login when the catalog rejects the credentials
+var ErrLoginFailed = errors.New("aspen: login failed")
+
+// ErrNoSessionCookie is returned by Login when the catalog accepts the
+// credentials but sets no cookie named CookieName
+var ErrNoSessionCookie = errors.New("aspen: no session cookie")
+
+// NewClient creates a client for the catalog at baseURL with its own cookie jar.
+func NewClient(baseURL, patronID string, timeout time.Duration) *Client {
+	jar, _ := cookiejar.New(nil)
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		PatronID:   patronID,
+		CookieName: DefaultCookieName,
+		HTTPClient: &http.Client{
+			Timeout: timeout,
+			Jar:     jar,
+		},
+		Jar: jar,
+	}
+}
+
+// Login signs the patron in. The session cookie is kept in the client's jar and
+// the credentials are remembered so an expired session can be renewed.
+func (c *Client) Login(username, password string) (Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, err := c.login(username, password)
+	if err != nil {
+		return result, err
+	}
+	c.username = username
+	c.password = password
+	c.session++
+	c.result = result
+	return result, nil
+}
+
+// LoginResult returns the patron information of the current session, or the
+// zero Result when the client has not logged in
+func (c *Client) LoginResult() Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result
+}
+
+func (c *Client) login(username, password string) (Result, error) {
+	data := url.Values{
+		"username": {username},
+		"password": {password},
+	}
+	resp, err := c.HTTPClient.PostForm(c.BaseURL+"/AJAX/JSON?method=loginUser", data)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("aspen: login returned %s", resp.Status)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, err
+	}
+	respJson := loginResp{}
+	if err := json.Unmarshal(bodyBytes, &respJson); err != nil {
+		return Result{}, err
+	}
+	if !respJson.Result.Success {
+		return respJson.Result, ErrLoginFailed
+	}
+	// Without the session cookie the account requests would not be logged
+	// in, which usually means CookieName does not match the catalog
+	if c.SessionCookie() == "" {
+		return respJson.Result, fmt.Errorf("%w: no %q cookie was set", ErrNoSessionCookie, c.CookieName)
+	}
+	return respJson.Result, nil
+}
+
+// ReadingHistoryPage fetches a single page of the reading history, sorted by checkout date.
+func (c *Client) ReadingHistoryPage(page int) (History, error) {
+	respJson := History{}
+	bodyBytes, err := c.getAccountBytes(c.readingHistoryURL(page))
+	if err != nil {
+		return respJson, err
+	}
+	if err := json.Unmarshal(bodyBytes, &respJson); err != nil {
+		return respJson, err
+	}
+	return respJson, nil
+}
+
+// TotalPages returns the number of pages in the reading history.
+func (c *Client) TotalPages() (int, error) {
+	doc, err := c.getAccountDocument(c.readingHistoryURL(1))
+	if err != nil {
+		return 0, err
+	}
+
+	// The last pagination link reads like "[12]"
+	var pageStr string
+	doc.Find("a[onclick]").Last().Each(func(i int, s *goquery.Selection) {
+		pageStr = s.Text()
+	})
+	re := regexp.MustCompile(`\[(\d+)]`)
+	match := re.FindStringSubmatch(pageStr)
+	if len(match) > 1 {
+		number, _ := strconv.Atoi(match[1])
+		return number, nil
+	}
+	return 1, nil
+}
+
+// SessionCookie returns the value of the session cookie, or "" when not logged in.
+func (c *Client) SessionCookie() string {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+	for _, cookie := range c.Jar.Cookies(u) {
+		if cookie.Name == c.CookieName {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+func (c *Client) readingHistoryURL(page int) string {
+	return fmt.Sprintf("%s/MyAccount/AJAX?method=getReadingHistory&patronId=%s&sort=checkedOut&page=%d&readingHistoryFilter=", c.BaseURL, c.PatronID, page)
+}
+
+// get performs a GET request with the session cookie attached.
+func (c *Client) get(u string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.HTTPClient.Do(req)
+}
+
+func (c *Client) getDocument(u string) (*goquery.Document, error) {
+	resp, err := c.get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return goquery.NewDocumentFromReader(resp.Body)
+}
@@ -0,0 +1,106 @@
+package aspen
+
+import (
+	"errors"
+	"github.com/PuerkitoBio/goquery"
+	"isbnAPI/money"
+	"strings"
+)
+
+// Fine is a fine or fee charged to the patron
+type Fine struct {
+	Date   Timestamp `json:"date"`
+	Reason string    `json:"reason"`
+	Title  string    `json:"title"`
+	// Amount is the fine as charged and Outstanding what is left to pay.
+	// Payments and credits are negative so the amounts can be summed.
+	Amount      money.Money `json:"amount"`
+	Outstanding money.Money `json:"outstanding"`
+}
+
+// Fines returns the fines and fees of the patron and of the linked accounts.
+// Amounts printed without a currency are read in currency.
+func (c *Client) Fines(currency string) ([]Fine, error) {
+	doc, err := c.getAccountDocument(c.BaseURL + "/MyAccount/Fines")
+	if err != nil {
+		return nil, err
+	}
+	return parseFines(doc, currency), nil
+}
+
+// parseFines reads the tables of the fines page, one per linked account
+func parseFines(doc *goquery.Document, currency string) []Fine {
+	var fines []Fine
+	doc.Find("table").Each(func(i int, table *goquery.Selection) {
+		columns := fineColumns(table)
+		if _, ok := columns["amount"]; !ok {
+			return
+		}
+		// The total is printed in the footer or in a last row reading "Total"
+		table.Find("tr").Not("thead tr, tfoot tr").Each(func(i int, row *goquery.Selection) {
+			cells := row.Find("td")
+			if cells.Length() == 0 || strings.EqualFold(strings.TrimSpace(cells.First().Text()), "total") {
+				return
+			}
+			cell := func(column string) string {
+				index, ok := columns[column]
+				if !ok {
+					return ""
+				}
+				return strings.Join(strings.Fields(cells.Eq(index).Text()), " ")
+			}
+			fine := Fine{
+				Reason: cell("reason"),
+				Title:  cell("title"),
+			}
+			fine.Date, _ = ParseTimestamp(cell("date"))
+			amount, err := parseAmount(cell("amount"), currency)
+			if err != nil {
+				return
+			}
+			fine.Amount, fine.Outstanding = amount, amount
+			if outstanding, err := parseAmount(cell("outstanding"), currency); err == nil {
+				fine.Outstanding = outstanding
+			}
+			fines = append(fines, fine)
+		})
+	})
+	return fines
+}
+
+// fineColumns maps the columns of a fines table to their index, reading
+// headers such as "Date", "Message", "Title", "Fine/Fee Amount" and
+// "Amount Outstanding"
+func fineColumns(table *goquery.Selection) map[string]int {
+	columns := map[string]int{}
+	table.Find("tr").First().Find("th").Each(func(i int, th *goquery.Selection) {
+		header := strings.ToLower(th.Text())
+		column := ""
+		switch {
+		case strings.Contains(header, "date"):
+			column = "date"
+		case strings.Contains(header, "outstanding"), strings.Contains(header, "balance"), strings.Contains(header, "owed"):
+			column = "outstanding"
+		case strings.Contains(header, "amount"), strings.Contains(header, "fine"), strings.Contains(header, "fee"):
+			column = "amount"
+		case strings.Contains(header, "title"):
+			column = "title"
+		case strings.Contains(header, "reason"), strings.Contains(header, "message"),
+			strings.Contains(header, "type"), strings.Contains(header, "description"):
+			column = "reason"
+		}
+		if _, ok := columns[column]; column != "" && !ok {
+			columns[column] = i
+		}
+	})
+	return columns
+}
+
+// parseAmount reads an amount such as "$5.00", or "5.00" in currency
+func parseAmount(s, currency string) (money.Money, error) {
+	m, err := money.Parse(s)
+	if err == nil || errors.Is(err, money.ErrNoAmount) {
+		return m, err
+	}
+	return money.Parse(s + " " + currency)
+}
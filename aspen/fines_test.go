@@ -0,0 +1,68 @@
+package aspen
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"isbnAPI/money"
+	"strings"
+	"testing"
+)
+
+const finesPage = `<table>
+<thead><tr><th>Date</th><th>Message</th><th>Title</th><th>Fine/Fee Amount</th><th>Amount Outstanding</th></tr></thead>
+<tbody>
+<tr><td>10/01/2026</td><td>Overdue</td><td>Dune</td><td>$5.00</td><td>$5.00</td></tr>
+<tr><td>10/02/2026</td><td>Lost item</td><td>Emma</td><td>$12.50</td><td>$10.00</td></tr>
+<tr><td>10/03/2026</td><td>Credit</td><td></td><td>-$2.00</td><td>-$2.00</td></tr>
+<tr><td>10/04/2026</td><td>Payment</td><td></td><td>($1.50)</td><td>($1.50)</td></tr>
+<tr><td>Total</td><td></td><td></td><td>$14.00</td><td>$11.50</td></tr>
+</tbody>
+</table>`
+
+func TestParseFines(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(finesPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fines := parseFines(doc, "USD")
+	want := []struct {
+		reason, amount, outstanding string
+	}{
+		{"Overdue", "5.00 USD", "5.00 USD"},
+		{"Lost item", "12.50 USD", "10.00 USD"},
+		{"Credit", "-2.00 USD", "-2.00 USD"},
+		{"Payment", "-1.50 USD", "-1.50 USD"},
+	}
+	if len(fines) != len(want) {
+		t.Fatalf("parseFines read %d fines, want %d", len(fines), len(want))
+	}
+	balance := money.Totals{}
+	for i, fine := range fines {
+		w := want[i]
+		if fine.Reason != w.reason || fine.Amount.String() != w.amount || fine.Outstanding.String() != w.outstanding {
+			t.Errorf("fine %d = %s %s %s, want %s %s %s", i, fine.Reason, fine.Amount, fine.Outstanding,
+				w.reason, w.amount, w.outstanding)
+		}
+		balance.Add(fine.Outstanding)
+	}
+	if got := balance["USD"].String(); got != "11.50 USD" {
+		t.Errorf("balance = %s, want 11.50 USD", got)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"$5.00", "5.00 USD"},
+		{"5.00", "5.00 EUR"},
+		{"-2.00", "-2.00 EUR"},
+		{"(3.25)", "-3.25 EUR"},
+		{"£1.20", "1.20 GBP"},
+	}
+	for _, tt := range tests {
+		got, err := parseAmount(tt.in, "EUR")
+		if err != nil || got.String() != tt.want {
+			t.Errorf("parseAmount(%q) = %s, %v, want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
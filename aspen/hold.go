@@ -0,0 +1,256 @@
+package aspen
+
+import (
+	"errors"
+	"github.com/PuerkitoBio/goquery"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hold is a title the patron placed a hold on
+type Hold struct {
+	// ID identifies the hold in the requests of this package
+	ID       string `json:"id"`
+	RecordId string `json:"recordId"`
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	Format   string `json:"format"`
+	// Source is "ils" for the library's own copies, otherwise the lending
+	// service, e.g. "overdrive"
+	Source string `json:"source"`
+	// Available is set once the title waits for the patron at the pickup location
+	Available bool   `json:"available"`
+	Status    string `json:"status"`
+	// Position is the place in the queue and QueueLength the number of
+	// holds on the title, 0 when the library does not show them
+	Position       int       `json:"position"`
+	QueueLength    int       `json:"queueLength"`
+	PickupLocation string    `json:"pickupLocation"`
+	Placed         Timestamp `json:"placed"`
+	// Expiration is the last day to pick up an available hold, or the day
+	// a pending hold lapses
+	Expiration Timestamp `json:"expiration"`
+	Frozen     bool      `json:"frozen"`
+
+	CanCancel       bool `json:"canCancel"`
+	CanFreeze       bool `json:"canFreeze"`
+	CanThaw         bool `json:"canThaw"`
+	CanChangePickup bool `json:"canChangePickup"`
+
+	// patronId, holdId and cancelId are the arguments Aspen expects to
+	// change the hold, and module the AJAX module handling it
+	patronId string
+	holdId   string
+	cancelId string
+	module   string
+}
+
+// HoldResult is the outcome of an action on a hold
+type HoldResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	// Message is the library's answer, e.g. why the hold cannot be frozen
+	Message string `json:"message"`
+}
+
+// ErrHoldNotFound is returned when the patron has no hold with the given id
+var ErrHoldNotFound = errors.New("aspen: hold not found")
+
+var (
+	// holdClassRe matches the class naming a hold row, e.g.
+	// "ilsHold_ils_12345" or "overdriveHold_6f1c..."
+	holdClassRe = regexp.MustCompile(`^(?:ilsHold_([A-Za-z0-9]+)_(.+)|(.+?)_?[Hh]old_(.+))$`)
+	// holdCallRe matches the calls made by the hold buttons, e.g.
+	// "AspenDiscovery.Account.freezeHold('42', '12345', '987', false, 'Freeze')"
+	holdCallRe = regexp.MustCompile(`AspenDiscovery\.(\w+)\.(\w+)\(([^)]*)\)`)
+	// positionRe reads the queue position, e.g. "3 of 12"
+	positionRe = regexp.MustCompile(`(\d+)(?:\s+of\s+(\d+))?`)
+	// availableRe matches the status of a hold waiting for pickup
+	availableRe = regexp.MustCompile(`(?i)\b(available|ready)\b`)
+)
+
+// Holds returns the holds of the patron, from the library and from every
+// lending service linked to the account.
+func (c *Client) Holds() ([]Hold, error) {
+	rows, err := c.accountResults("getHolds", "holds")
+	if err != nil {
+		return nil, err
+	}
+
+	var holds []Hold
+	rows.Each(func(i int, s *goquery.Selection) {
+		holds = append(holds, parseHold(s))
+	})
+	return holds, nil
+}
+
+// parseHold reads a hold row of the holds page
+func parseHold(s *goquery.Selection) Hold {
+	hold := Hold{Title: strings.TrimSpace(s.Find(".result-title").First().Text())}
+
+	values := resultValues(s)
+	hold.Author = values["author"]
+	hold.Format = values["format"]
+	hold.Status = values["status"]
+	hold.PickupLocation = firstValue(values, "pickup location", "pickup", "pickup at")
+	hold.Placed = dateIn(firstValue(values, "date placed", "placed"))
+	hold.Expiration = dateIn(firstValue(values, "pickup by", "expires", "expiration date", "expiration"))
+	if match := positionRe.FindStringSubmatch(firstValue(values, "position", "position in queue", "queue position")); match != nil {
+		hold.Position, _ = strconv.Atoi(match[1])
+		hold.QueueLength, _ = strconv.Atoi(match[2])
+	}
+
+	hold.Available = inAvailableSection(s) || availableRe.MatchString(hold.Status)
+	hold.Frozen = strings.Contains(strings.ToLower(hold.Status), "frozen") ||
+		strings.Contains(strings.ToLower(hold.Status), "suspended")
+
+	hold.Source, hold.RecordId = resultSource(s, holdClassRe)
+
+	// The buttons carry the arguments of the hold actions
+	s.Find("[onclick]").Each(func(i int, button *goquery.Selection) {
+		match := holdCallRe.FindStringSubmatch(button.AttrOr("onclick", ""))
+		if match == nil {
+			return
+		}
+		args := callArgs(match[3])
+		arg := func(i int) string {
+			if i < len(args) {
+				return args[i]
+			}
+			return ""
+		}
+		hold.module = match[1]
+		if hold.patronId == "" {
+			hold.patronId = arg(0)
+		}
+		if hold.RecordId == "" {
+			hold.RecordId = arg(1)
+		}
+		// Every call takes the patron, the record and the hold, e.g.
+		// changeHoldPickupLocation('42', '12345', '987', 'main'). Only
+		// cancelHold may name the hold differently.
+		function := strings.ToLower(match[2])
+		switch {
+		case strings.Contains(function, "cancel"):
+			hold.CanCancel = true
+			hold.cancelId = arg(2)
+			return
+		case strings.Contains(function, "thaw"):
+			hold.CanThaw = true
+		case strings.Contains(function, "freeze"):
+			hold.CanFreeze = true
+		case strings.Contains(function, "location"):
+			hold.CanChangePickup = true
+		default:
+			return
+		}
+		if hold.holdId == "" {
+			hold.holdId = arg(2)
+		}
+	})
+	if hold.holdId == "" {
+		hold.holdId = hold.cancelId
+	}
+	if hold.cancelId == "" {
+		hold.cancelId = hold.holdId
+	}
+
+	parts := []string{hold.Source, hold.RecordId}
+	if hold.holdId != "" && hold.holdId != hold.RecordId {
+		parts = append(parts, hold.holdId)
+	}
+	hold.ID = joinID(parts...)
+	return hold
+}
+
+// inAvailableSection reports whether the row is inside the section listing
+// the available holds, e.g. "availableHoldsPlaceholder" rather than
+// "unavailableHoldsPlaceholder"
+func inAvailableSection(s *goquery.Selection) bool {
+	for parent := s.Parent(); parent.Length() > 0; parent = parent.Parent() {
+		name := strings.ToLower(parent.AttrOr("id", "") + " " + parent.AttrOr("class", ""))
+		if strings.Contains(strings.ReplaceAll(name, "unavailable", ""), "available") {
+			return true
+		}
+	}
+	return false
+}
+
+// PlaceHold places a hold on the catalog record with the given id, to be
+// picked up at the location with the given code
+func (c *Client) PlaceHold(recordId, pickupLocation string) (HoldResult, error) {
+	result, err := c.action("Record", "placeHold", url.Values{
+		"id":           {recordId},
+		"selectedUser": {c.PatronID},
+		"pickupBranch": {pickupLocation},
+	})
+	if err != nil {
+		return HoldResult{}, err
+	}
+	return HoldResult{ID: recordId, Success: result.Success, Message: result.Message}, nil
+}
+
+// CancelHold cancels the hold with the given id
+func (c *Client) CancelHold(id string) (HoldResult, error) {
+	return c.holdAction(id, "cancelHold", func(h Hold, form url.Values) {
+		form.Set("cancelId", h.cancelId)
+	})
+}
+
+// FreezeHold suspends the hold with the given id so the patron keeps their
+// place in the queue. The library reactivates it on reactivate, unless it is zero.
+func (c *Client) FreezeHold(id string, reactivate time.Time) (HoldResult, error) {
+	return c.holdAction(id, "freezeHold", func(h Hold, form url.Values) {
+		if !reactivate.IsZero() {
+			form.Set("reactivationDate", reactivate.Format("2006-01-02"))
+		}
+	})
+}
+
+// ThawHold reactivates the frozen hold with the given id
+func (c *Client) ThawHold(id string) (HoldResult, error) {
+	return c.holdAction(id, "thawHold", nil)
+}
+
+// ChangePickupLocation moves the hold with the given id to the location with the given code
+func (c *Client) ChangePickupLocation(id, location string) (HoldResult, error) {
+	return c.holdAction(id, "changeHoldLocation", func(h Hold, form url.Values) {
+		form.Set("newLocation", location)
+	})
+}
+
+// holdAction finds the hold with the given id and calls method on it. args
+// adds the arguments specific to the method.
+func (c *Client) holdAction(id, method string, args func(Hold, url.Values)) (HoldResult, error) {
+	holds, err := c.Holds()
+	if err != nil {
+		return HoldResult{}, err
+	}
+	for _, hold := range holds {
+		if hold.ID != id {
+			continue
+		}
+		if hold.patronId == "" {
+			return HoldResult{ID: id, Message: "the library does not offer to change this hold"}, nil
+		}
+
+		form := url.Values{
+			"patronId": {hold.patronId},
+			"recordId": {hold.RecordId},
+			"holdId":   {hold.holdId},
+		}
+		module := actionModule(hold.module, form, hold.RecordId)
+		if args != nil {
+			args(hold, form)
+		}
+		result, err := c.action(module, method, form)
+		if err != nil {
+			return HoldResult{}, err
+		}
+		return HoldResult{ID: id, Success: result.Success, Message: result.Message}, nil
+	}
+	return HoldResult{}, ErrHoldNotFound
+}
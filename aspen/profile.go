@@ -0,0 +1,82 @@
+package aspen
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"regexp"
+	"strings"
+)
+
+// Profile is the patron information of the account: the details returned
+// by the login and the ones only shown on the account pages
+type Profile struct {
+	Result
+	// CardExpires is when the library card expires, zero when the library does not show it
+	CardExpires Timestamp `json:"cardExpires"`
+	// LinkedAccounts are the other library cards the patron manages from this account
+	LinkedAccounts []LinkedAccount `json:"linkedAccounts"`
+}
+
+// LinkedAccount is a library card linked to the patron's account
+type LinkedAccount struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// removeLinkedUserRe matches the button removing a linked account, e.g.
+// "AspenDiscovery.Account.removeLinkedUser(1234)"
+var removeLinkedUserRe = regexp.MustCompile(`removeLinkedUser\(\s*['"]?([^'")\s]+)`)
+
+// Profile returns the patron information of the current session with the
+// card expiration and the linked accounts read from the account pages.
+func (c *Client) Profile() (Profile, error) {
+	profile := Profile{Result: c.LoginResult(), LinkedAccounts: []LinkedAccount{}}
+
+	doc, err := c.getAccountDocument(c.BaseURL + "/MyAccount/ContactInformation")
+	if err != nil {
+		return profile, err
+	}
+	profile.CardExpires = dateIn(labelValue(doc, "expiration date", "expires", "card expires", "card expiration"))
+
+	doc, err = c.getAccountDocument(c.BaseURL + "/MyAccount/LinkedAccounts")
+	if err != nil {
+		return profile, err
+	}
+	// Each linked account is listed with a button to remove it, which holds its id
+	doc.Find("[onclick*='removeLinkedUser']").Each(func(i int, button *goquery.Selection) {
+		match := removeLinkedUserRe.FindStringSubmatch(button.AttrOr("onclick", ""))
+		if match == nil {
+			return
+		}
+		row := button.Closest("tr, li, .row")
+		name := strings.TrimSpace(row.Find("td, .linked-user-name, strong").First().Text())
+		if name == "" {
+			name = strings.TrimSpace(strings.Replace(row.Text(), button.Text(), "", 1))
+		}
+		profile.LinkedAccounts = append(profile.LinkedAccounts, LinkedAccount{
+			ID:   match[1],
+			Name: strings.Join(strings.Fields(name), " "),
+		})
+	})
+	return profile, nil
+}
+
+// labelValue returns the value printed next to the first label matching one
+// of labels, e.g. "<strong>Expiration Date</strong> <span>12/31/2026</span>"
+func labelValue(doc *goquery.Document, labels ...string) string {
+	value := ""
+	doc.Find("strong, label, th, dt, .result-label, .control-label").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		text := strings.ToLower(strings.Trim(strings.TrimSpace(s.Text()), ":"))
+		for _, label := range labels {
+			if text != label {
+				continue
+			}
+			value = strings.TrimSpace(s.Next().Text())
+			if value == "" {
+				value = strings.TrimSpace(s.Parent().Next().Text())
+			}
+			return value == ""
+		}
+		return true
+	})
+	return value
+}
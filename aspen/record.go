@@ -0,0 +1,79 @@
+package aspen
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"isbnAPI/isbn"
+	"regexp"
+	"strings"
+)
+
+// Record is the detail page of a catalog record
+type Record struct {
+	// ISBNs lists the valid ISBNs shown on the record page in canonical form,
+	// without duplicates, in page order
+	ISBNs []string
+	// Qualifiers holds the edition qualifier printed after an ISBN, such as
+	// "paperback" or "ebook", keyed by canonical ISBN
+	Qualifiers map[string]string
+}
+
+var (
+	// brRe matches the line breaks separating the ISBNs of a record
+	brRe = regexp.MustCompile(`(?i)<br\s*/?>`)
+	// isbnCandidateRe matches an ISBN-10 or ISBN-13 that may contain hyphens or spaces
+	isbnCandidateRe = regexp.MustCompile(`[0-9][0-9\- ]{8,15}[0-9Xx]`)
+)
+
+// Record fetches the detail page of a record given its link from the reading history.
+func (c *Client) Record(linkUrl string) (Record, error) {
+	doc, err := c.getDocument(c.BaseURL + linkUrl)
+	if err != nil {
+		return Record{}, err
+	}
+
+	record := Record{Qualifiers: map[string]string{}}
+	// Each div.result-label reading "ISBN" is followed by a value holding one
+	// ISBN per line, e.g. "9780316769488 (paperback)<br/>0316769487"
+	doc.Find("div.result-label:contains('ISBN')").Each(func(i int, label *goquery.Selection) {
+		html, err := label.Next().Html()
+		if err != nil {
+			return
+		}
+		lines, err := goquery.NewDocumentFromReader(strings.NewReader(brRe.ReplaceAllString(html, "\n")))
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(lines.Text(), "\n") {
+			record.addISBN(line)
+		}
+	})
+	return record, nil
+}
+
+// addISBN parses an ISBN line of the record page and records it once
+func (r *Record) addISBN(line string) {
+	loc := isbnCandidateRe.FindStringIndex(line)
+	if loc == nil {
+		return
+	}
+	candidate := line[loc[0]:loc[1]]
+	i, err := isbn.Parse(candidate)
+	if err != nil {
+		// The match may have run into a following number
+		if i, err = isbn.Parse(strings.Fields(candidate)[0]); err != nil {
+			return
+		}
+	}
+	// An ISBN-10 and its ISBN-13 are the same edition
+	for _, known := range r.ISBNs {
+		if isbn.MustParse(known).To13() == i.To13() {
+			return
+		}
+	}
+	s := i.String()
+	r.ISBNs = append(r.ISBNs, s)
+	qualifier := strings.Trim(strings.TrimSpace(line[loc[1]:]), "():;,. ")
+	if qualifier != "" {
+		r.Qualifiers[s] = strings.ToLower(qualifier)
+	}
+}
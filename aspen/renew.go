@@ -0,0 +1,98 @@
+package aspen
+
+import (
+	"errors"
+	"net/url"
+)
+
+// Renewal is the outcome of renewing a checkout
+type Renewal struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Success bool   `json:"success"`
+	// DueDate is the due date read back after the renewal
+	DueDate Timestamp `json:"dueDate"`
+	// Message is the library's answer, e.g. why the title cannot be renewed
+	Message string `json:"message"`
+}
+
+// ErrCheckoutNotFound is returned by Renew when the patron has no checkout with the given id
+var ErrCheckoutNotFound = errors.New("aspen: checkout not found")
+
+// Renew renews the checkout with the given id
+func (c *Client) Renew(id string) (Renewal, error) {
+	checkouts, err := c.Checkouts()
+	if err != nil {
+		return Renewal{}, err
+	}
+	for _, checkout := range checkouts {
+		if checkout.ID == id {
+			renewals := []Renewal{c.renew(checkout)}
+			c.readDueDates(renewals)
+			return renewals[0], nil
+		}
+	}
+	return Renewal{}, ErrCheckoutNotFound
+}
+
+// RenewAll tries to renew every checkout of the patron. The titles are
+// renewed one by one so each gets its own result and message.
+func (c *Client) RenewAll() ([]Renewal, error) {
+	checkouts, err := c.Checkouts()
+	if err != nil {
+		return nil, err
+	}
+	renewals := make([]Renewal, len(checkouts))
+	for i, checkout := range checkouts {
+		renewals[i] = c.renew(checkout)
+	}
+	c.readDueDates(renewals)
+	return renewals, nil
+}
+
+// renew asks the module lending the checkout to renew it. Errors are
+// reported in the message of the renewal so one failure does not hide the
+// results of the other titles.
+func (c *Client) renew(checkout Checkout) Renewal {
+	renewal := Renewal{ID: checkout.ID, Title: checkout.Title, DueDate: checkout.DueDate}
+	if checkout.patronId == "" {
+		renewal.Message = "the library does not offer to renew this title"
+		return renewal
+	}
+
+	form := url.Values{
+		"patronId": {checkout.patronId},
+		"recordId": {checkout.RecordId},
+	}
+	if checkout.renewIndicator != "" {
+		form.Set("renewIndicator", checkout.renewIndicator)
+	}
+	module := actionModule(checkout.renewModule, form, checkout.RecordId)
+
+	result, err := c.action(module, "renewCheckout", form)
+	if err != nil {
+		renewal.Message = err.Error()
+		return renewal
+	}
+	renewal.Success = result.Success
+	renewal.Message = result.Message
+	return renewal
+}
+
+// readDueDates reads the checkouts again to fill in the due dates after the
+// renewals. The due dates are left as they were when the checkouts cannot be read.
+func (c *Client) readDueDates(renewals []Renewal) {
+	checkouts, err := c.Checkouts()
+	if err != nil {
+		return
+	}
+	dueDates := make(map[string]Timestamp, len(checkouts))
+	for _, checkout := range checkouts {
+		dueDates[checkout.ID] = checkout.DueDate
+	}
+	for i := range renewals {
+		if due, ok := dueDates[renewals[i].ID]; ok {
+			renewals[i].DueDate = due
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package aspen
+
+import (
+	"encoding/json"
+	"github.com/PuerkitoBio/goquery"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// dateInRe matches a date in a column that may hold other text, such as
+	// an "OVERDUE" label
+	dateInRe = regexp.MustCompile(`\d{1,2}/\d{1,2}/\d{2,4}|\d{4}-\d{2}-\d{2}`)
+	// unsafeIDRe matches the characters left out of ids so they fit in a URL path
+	unsafeIDRe = regexp.MustCompile(`[^A-Za-z0-9_.:-]+`)
+)
+
+// accountResults fetches an account list such as the checkouts or the holds
+// and returns its rows. The list is rendered as HTML in the field of the
+// JSON response named after it.
+func (c *Client) accountResults(method, field string) (*goquery.Selection, error) {
+	bodyBytes, err := c.getAccountBytes(c.BaseURL + "/MyAccount/AJAX?method=" + method + "&source=all")
+	if err != nil {
+		return nil, err
+	}
+	var respJson map[string]json.RawMessage
+	if err := json.Unmarshal(bodyBytes, &respJson); err != nil {
+		return nil, err
+	}
+	var html string
+	if raw, ok := respJson[field]; ok {
+		if err := json.Unmarshal(raw, &html); err != nil {
+			return nil, err
+		}
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, err
+	}
+	return doc.Find(".result.row"), nil
+}
+
+// resultValues reads the details of a row, which are pairs of .result-label
+// and .result-value such as "Due" and "10/21/26", keyed by lower case label
+func resultValues(s *goquery.Selection) map[string]string {
+	values := map[string]string{}
+	s.Find(".result-label").Each(func(i int, label *goquery.Selection) {
+		key := strings.ToLower(strings.Trim(strings.TrimSpace(label.Text()), ":"))
+		values[key] = strings.Join(strings.Fields(label.Next().Text()), " ")
+	})
+	return values
+}
+
+// firstValue returns the value of the first of keys that is set
+func firstValue(values map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if values[key] != "" {
+			return values[key]
+		}
+	}
+	return ""
+}
+
+// resultSource reads the source and record id of a row from the class
+// naming it. classRe matches the library's own rows, e.g. "ilsCheckout_ils_12345",
+// with the source and record as its first two groups, and the rows of a
+// lending service, e.g. "overdrive_checkout_6f1c...", with its last two groups.
+func resultSource(s *goquery.Selection, classRe *regexp.Regexp) (source, recordId string) {
+	for _, class := range strings.Fields(s.AttrOr("class", "")) {
+		match := classRe.FindStringSubmatch(class)
+		if match == nil {
+			continue
+		}
+		if match[1] != "" {
+			return "ils", match[2]
+		}
+		return strings.ToLower(match[3]), match[4]
+	}
+	return "", ""
+}
+
+// callArgs splits the arguments of a button's JavaScript call, e.g.
+// "'42', '12345', '987'", and removes their quotes
+func callArgs(s string) []string {
+	args := strings.Split(s, ",")
+	for i := range args {
+		args[i] = strings.Trim(strings.TrimSpace(args[i]), `'"`)
+	}
+	return args
+}
+
+// dateIn reads a date that may be surrounded by other text
+func dateIn(s string) Timestamp {
+	if date := dateInRe.FindString(s); date != "" {
+		s = date
+	}
+	t, _ := ParseTimestamp(s)
+	return t
+}
+
+// joinID joins the parts identifying a row, e.g. "ils-12345-67890"
+func joinID(parts ...string) string {
+	return unsafeIDRe.ReplaceAllString(strings.Join(parts, "-"), "_")
+}
+
+// actionModule returns the AJAX module handling an action on a row whose
+// buttons call AspenDiscovery.<module>. The buttons call
+// AspenDiscovery.Account for the library's own copies, which Aspen serves
+// as MyAccount, and the lending service's module otherwise. OverDrive also
+// expects the record as overDriveId in form.
+func actionModule(module string, form url.Values, recordId string) string {
+	if module == "" || strings.EqualFold(module, "Account") {
+		return "MyAccount"
+	}
+	if strings.EqualFold(module, "OverDrive") {
+		form.Set("overDriveId", recordId)
+	}
+	return module
+}
@@ -0,0 +1,132 @@
+package aspen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/PuerkitoBio/goquery"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrSessionExpired is returned when an account request is still rejected
+// after logging in again, or when the client has no credentials to do so.
+var ErrSessionExpired = errors.New("aspen: session expired")
+
+// loginFormRe matches the login form Aspen renders in place of account content.
+// The markup may be escaped inside a JSON string.
+var loginFormRe = regexp.MustCompile(`(?i)<form[^>]+id=\\?["']loginForm`)
+
+// loginMessageRe matches the message of an action refused because the patron
+// is not logged in, e.g. "You must be logged in to renew titles"
+var loginMessageRe = regexp.MustCompile(`(?i)\b(logged in|log in|login)\b`)
+
+// getAccountBytes fetches an account resource. When the response shows the
+// session has expired the client logs in again and retries the request once.
+func (c *Client) getAccountBytes(u string) ([]byte, error) {
+	return c.accountBytes(func() (*http.Response, error) { return c.get(u) }, sessionExpired)
+}
+
+// postAccountAction performs an account action such as a renewal. A refused
+// action also answers success:false, so only a response that asks the patron
+// to log in renews the session.
+func (c *Client) postAccountAction(u string, form url.Values) ([]byte, error) {
+	return c.accountBytes(func() (*http.Response, error) { return c.HTTPClient.PostForm(u, form) }, loggedOut)
+}
+
+// accountBytes performs the request made by do, logging in again and
+// retrying once when expired reports that the session has expired.
+func (c *Client) accountBytes(do func() (*http.Response, error), expired func(*http.Response, []byte) bool) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		session := c.currentSession()
+		resp, err := do()
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if !expired(resp, body) {
+			return body, nil
+		}
+		if attempt > 0 {
+			return nil, ErrSessionExpired
+		}
+		if err := c.relogin(session); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) getAccountDocument(u string) (*goquery.Document, error) {
+	body, err := c.getAccountBytes(u)
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(body))
+}
+
+func (c *Client) currentSession() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// relogin logs in again with the remembered credentials unless another
+// request already renewed the session since session was observed.
+func (c *Client) relogin(session int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.session != session {
+		return nil
+	}
+	if c.username == "" {
+		return ErrSessionExpired
+	}
+	result, err := c.login(c.username, c.password)
+	if err != nil {
+		return err
+	}
+	c.session++
+	c.result = result
+	return nil
+}
+
+// sessionExpired reports whether the response to an account request shows
+// that the patron is no longer logged in.
+func sessionExpired(resp *http.Response, body []byte) bool {
+	if loggedOut(resp, body) {
+		return true
+	}
+	// AJAX methods answer with success:false
+	var status struct {
+		Success *bool `json:"success"`
+	}
+	return json.Unmarshal(body, &status) == nil && status.Success != nil && !*status.Success
+}
+
+// loggedOut reports whether the response asks the patron to log in
+func loggedOut(resp *http.Response, body []byte) bool {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return true
+	}
+	// Account pages redirect to the login page
+	if resp.Request != nil && strings.Contains(strings.ToLower(resp.Request.URL.Path), "/myaccount/login") {
+		return true
+	}
+	// AJAX actions answer with a message asking to log in
+	var status struct {
+		Success *bool  `json:"success"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &status) == nil && status.Success != nil && !*status.Success && loginMessageRe.MatchString(status.Message) {
+		return true
+	}
+	// or render the login form instead of the content
+	return loginFormRe.Match(body)
+}
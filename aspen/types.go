@@ -0,0 +1,121 @@
+package aspen
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp is a date from an Aspen response. Aspen sends dates as Unix
+// timestamps, as numeric strings or as formatted dates depending on the
+// method, so all of them are accepted. It is encoded as RFC 3339, or null
+// when unknown.
+type Timestamp struct {
+	time.Time
+}
+
+// dateLayouts are the formatted dates found in Aspen responses and pages
+var dateLayouts = []string{
+	time.RFC3339, "2006-01-02 15:04:05", "2006-01-02", "01/02/2006", "1/2/2006",
+	"Jan 2, 2006", "January 2, 2006", "Mon, Jan 2, 2006", "Monday, January 2, 2006",
+	"01/02/06", "1/2/06",
+}
+
+// ParseTimestamp reads a date in one of the forms used by Aspen. Empty strings
+// and "0" give the zero Timestamp.
+func ParseTimestamp(s string) (Timestamp, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Timestamp{}, nil
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Timestamp{time.Unix(secs, 0).UTC()}, nil
+	}
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return Timestamp{t}, nil
+		}
+	}
+	return Timestamp{}, err
+}
+
+// UnmarshalJSON accepts null, a number of seconds or a string. A date in an
+// unknown format is read as the zero Timestamp rather than failing the
+// whole response.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte("false")) {
+		*t = Timestamp{}
+		return nil
+	}
+	s := string(data)
+	if data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	*t, _ = ParseTimestamp(s)
+	return nil
+}
+
+// MarshalJSON encodes the time as RFC 3339, or null when it is zero
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time.Format(time.RFC3339))
+}
+
+// Flag is a boolean from an Aspen response, which may be sent as a JSON
+// boolean, a number or a string such as "1" or "true"
+type Flag bool
+
+// UnmarshalJSON accepts booleans, numbers and strings. Unknown values are false.
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(bytes.TrimSpace(data)), `"`)
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		n, _ := strconv.ParseFloat(s, 64)
+		v = n != 0
+	}
+	*f = Flag(v)
+	return nil
+}
+
+// Count is a number from an Aspen response, which may be sent as a JSON
+// number or a numeric string
+type Count int
+
+// UnmarshalJSON accepts numbers and numeric strings. Other values are 0.
+func (c *Count) UnmarshalJSON(data []byte) error {
+	n, _ := strconv.Atoi(strings.Trim(string(bytes.TrimSpace(data)), `"`))
+	*c = Count(n)
+	return nil
+}
+
+// ID is an identifier from an Aspen response, which may be sent as a JSON
+// string or number
+type ID string
+
+// UnmarshalJSON accepts strings and numbers
+func (id *ID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*id = ""
+		return nil
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+	*id = ID(data)
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package cache is a small on-disk key/value cache whose entries expire
+// after a fixed time to live.
+package cache
+
+import (
+	"encoding/json"
+	"go.etcd.io/bbolt"
+	"sync/atomic"
+	"time"
+)
+
+var entriesBucket = []byte("entries")
+
+// Cache stores JSON encoded values on disk and counts its hits and misses
+type Cache struct {
+	db  *bbolt.DB
+	ttl time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// Stats are the counters of a cache since it was opened
+type Stats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+	TTL     string `json:"ttl"`
+}
+
+type entry struct {
+	Stored time.Time       `json:"stored"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// Open opens the cache at path, creating it when it does not exist.
+// Entries older than ttl are treated as missing.
+func Open(path string, ttl time.Duration) (*Cache, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(entriesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Cache{db: db, ttl: ttl}, nil
+}
+
+// Close closes the underlying database
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// Get decodes the value stored under key into v. It reports false when the
+// key is missing, expired or cannot be decoded.
+func (c *Cache) Get(key string, v interface{}) bool {
+	var raw []byte
+	c.db.View(func(tx *bbolt.Tx) error {
+		if data := tx.Bucket(entriesBucket).Get([]byte(key)); data != nil {
+			raw = append(raw, data...)
+		}
+		return nil
+	})
+
+	e := entry{}
+	if raw == nil || json.Unmarshal(raw, &e) != nil || time.Since(e.Stored) > c.ttl || json.Unmarshal(e.Value, v) != nil {
+		c.misses.Add(1)
+		return false
+	}
+	c.hits.Add(1)
+	return true
+}
+
+// Put stores v under key
+func (c *Cache) Put(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry{Stored: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(entriesBucket).Put([]byte(key), data)
+	})
+}
+
+// Stats returns the hit and miss counts and the number of stored entries
+func (c *Cache) Stats() Stats {
+	stats := Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		TTL:    c.ttl.String(),
+	}
+	c.db.View(func(tx *bbolt.Tx) error {
+		stats.Entries = tx.Bucket(entriesBucket).Stats().KeyN
+		return nil
+	})
+	return stats
+}
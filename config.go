@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"isbnAPI/aspen"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings of the library system the assistant talks to
+type Config struct {
+	// BaseURL is the root of the Aspen Discovery catalog
+	BaseURL string
+	// CookieName is the name of the session cookie set by the catalog
+	CookieName string
+	// Accounts are the library accounts served by the assistant. The first one is the default
+	Accounts []AccountConfig
+	// StoreFile is the path of the database keeping the reading history
+	StoreFile string
+	// ISBNCacheFile is the path of the database caching the ISBN lookups
+	ISBNCacheFile string
+	// ISBNCacheTTL is how long a cached ISBN lookup stays valid
+	ISBNCacheTTL time.Duration
+	// ISBNLegacyJSON makes /isbn/ answer with the map keyed by isbndb labels
+	ISBNLegacyJSON bool
+	// MetadataProviders names the book metadata sources, in order of preference
+	MetadataProviders []string
+	// ISBNdbBaseURL is the root of the isbndb site
+	ISBNdbBaseURL string
+	// OpenLibraryBaseURL is the root of the Open Library books API
+	OpenLibraryBaseURL string
+	// GoogleBooksBaseURL is the root of the Google Books API
+	GoogleBooksBaseURL string
+	// GoogleBooksAPIKey is the optional key of the Google Books API
+	GoogleBooksAPIKey string
+	// HomeCurrency is the currency the savings are reported in
+	HomeCurrency string
+	// RatesFile is the ECB XML or CSV file with the exchange rates, "" to disable conversion
+	RatesFile string
+}
+
+// AccountConfig holds the credentials of a single library account
+type AccountConfig struct {
+	// ID identifies the account in the ?account= query parameter
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// PatronID is the patron id of the library account
+	PatronID string `json:"patronId"`
+}
+
+const (
+	defaultBaseURL   = "https://discovery.roundrocktexas.gov"
+	defaultAccountID = "default"
+)
+
+// loadConfig reads the configuration from the environment, falling back to
+// the Round Rock Public Library defaults. The accounts are read from the JSON
+// file named by LIBRARY_ACCOUNTS_FILE, or built from the compiled-in
+// credentials when it is unset.
+func loadConfig() (Config, error) {
+	config := Config{
+		BaseURL:            getEnv("LIBRARY_BASE_URL", defaultBaseURL),
+		CookieName:         getEnv("LIBRARY_SESSION_COOKIE", aspen.DefaultCookieName),
+		StoreFile:          getEnv("LIBRARY_STORE_FILE", "library.db"),
+		ISBNCacheFile:      getEnv("ISBN_CACHE_FILE", "isbn_cache.db"),
+		ISBNdbBaseURL:      getEnv("ISBNDB_BASE_URL", "https://isbndb.com"),
+		OpenLibraryBaseURL: getEnv("OPENLIBRARY_BASE_URL", "https://openlibrary.org"),
+		GoogleBooksBaseURL: getEnv("GOOGLEBOOKS_BASE_URL", "https://www.googleapis.com"),
+		GoogleBooksAPIKey:  getEnv("GOOGLEBOOKS_API_KEY", ""),
+		HomeCurrency:       strings.ToUpper(getEnv("HOME_CURRENCY", "USD")),
+		RatesFile:          getEnv("FX_RATES_FILE", ""),
+	}
+	for _, name := range strings.Split(getEnv("METADATA_PROVIDERS", "isbndb,googlebooks"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			config.MetadataProviders = append(config.MetadataProviders, name)
+		}
+	}
+	ttl, err := time.ParseDuration(getEnv("ISBN_CACHE_TTL", "720h"))
+	if err != nil {
+		return config, fmt.Errorf("parsing ISBN_CACHE_TTL: %w", err)
+	}
+	config.ISBNCacheTTL = ttl
+	config.ISBNLegacyJSON, err = strconv.ParseBool(getEnv("ISBN_LEGACY_JSON", "false"))
+	if err != nil {
+		return config, fmt.Errorf("parsing ISBN_LEGACY_JSON: %w", err)
+	}
+
+	accountsFile := getEnv("LIBRARY_ACCOUNTS_FILE", "")
+	if accountsFile == "" {
+		config.Accounts = []AccountConfig{{
+			ID:       defaultAccountID,
+			Username: USERNAME,
+			Password: PASSWORD,
+			PatronID: getEnv("LIBRARY_PATRON_ID", USERID),
+		}}
+		return config, nil
+	}
+
+	data, err := os.ReadFile(accountsFile)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config.Accounts); err != nil {
+		return config, fmt.Errorf("parsing %s: %w", accountsFile, err)
+	}
+	if len(config.Accounts) == 0 {
+		return config, errors.New("no accounts configured in " + accountsFile)
+	}
+	seen := make(map[string]bool)
+	for _, a := range config.Accounts {
+		if a.ID == "" || seen[a.ID] {
+			return config, fmt.Errorf("account ids in %s must be unique and non-empty", accountsFile)
+		}
+		seen[a.ID] = true
+	}
+	return config, nil
+}
+
+// getEnv returns the environment variable key, or fallback when it is unset
+func getEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+	return fallback
+}
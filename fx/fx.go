@@ -0,0 +1,125 @@
+// Package fx converts money between currencies with exchange rates loaded
+// from a local file, so no network access is needed.
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"isbnAPI/money"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// ErrNoRate is returned when a currency has no rate in the loaded file
+var ErrNoRate = errors.New("fx: no exchange rate")
+
+// Rates holds daily exchange rates against a base currency. A rate is the
+// amount of the currency worth one unit of the base, as published by the ECB.
+type Rates struct {
+	// Base is the currency the rates are quoted against, EUR for ECB files
+	Base string
+	// days holds the dates with rates in ascending order
+	days  []time.Time
+	rates map[time.Time]map[string]*big.Rat
+}
+
+// NewRates returns an empty set of rates quoted against base
+func NewRates(base string) *Rates {
+	return &Rates{Base: base, rates: make(map[time.Time]map[string]*big.Rat)}
+}
+
+// Set records the rate of currency on day. The rate is a decimal string.
+func (r *Rates) Set(day time.Time, currency, rate string) error {
+	v, ok := new(big.Rat).SetString(rate)
+	if !ok || v.Sign() <= 0 {
+		return fmt.Errorf("fx: invalid rate %q for %s", rate, currency)
+	}
+	day = day.UTC().Truncate(24 * time.Hour)
+	dayRates, ok := r.rates[day]
+	if !ok {
+		dayRates = make(map[string]*big.Rat)
+		r.rates[day] = dayRates
+		i := sort.Search(len(r.days), func(i int) bool { return !r.days[i].Before(day) })
+		r.days = append(r.days, time.Time{})
+		copy(r.days[i+1:], r.days[i:])
+		r.days[i] = day
+	}
+	dayRates[currency] = v
+	return nil
+}
+
+// rate returns the rate of currency on the last day with a rate for it at
+// or before on. When on is zero or earlier than every rate, the earliest
+// rate is used for earlier dates and the latest for a zero date.
+func (r *Rates) rate(currency string, on time.Time) (*big.Rat, error) {
+	if currency == r.Base {
+		return big.NewRat(1, 1), nil
+	}
+	i := len(r.days) - 1
+	if !on.IsZero() {
+		i = sort.Search(len(r.days), func(i int) bool { return r.days[i].After(on) }) - 1
+	}
+	// Walk back over weekends and days missing the currency
+	for ; i >= 0; i-- {
+		if v, ok := r.rates[r.days[i]][currency]; ok {
+			return v, nil
+		}
+	}
+	// Dates before the file starts use the earliest rate
+	for _, day := range r.days {
+		if v, ok := r.rates[day][currency]; ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNoRate, currency)
+}
+
+// Convert returns m in currency to at the rates of the given day, rounded to
+// the minor unit of the target currency.
+func (r *Rates) Convert(m money.Money, to string, on time.Time) (money.Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	from, err := r.rate(m.Currency, on)
+	if err != nil {
+		return money.Money{}, err
+	}
+	target, err := r.rate(to, on)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	// units * 10^(expTo-expFrom) * target / from
+	amount := new(big.Rat).SetInt64(m.Units)
+	amount.Mul(amount, target)
+	amount.Quo(amount, from)
+	shift := money.Exponent(to) - money.Exponent(m.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		amount.Mul(amount, scale)
+	} else {
+		amount.Quo(amount, scale)
+	}
+	return money.Money{Units: round(amount), Currency: to}, nil
+}
+
+// round rounds x half away from zero
+func round(x *big.Rat) int64 {
+	num := new(big.Int).Abs(x.Num())
+	q, rem := new(big.Int).QuoRem(num, x.Denom(), new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(x.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if x.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,108 @@
+package fx
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Load reads the rates file at path. Files ending in .xml are read as ECB
+// reference rates, other files as CSV.
+func Load(path string) (*Rates, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		return LoadECB(f)
+	}
+	return LoadCSV(f)
+}
+
+// ecbEnvelope is the layout of the ECB eurofxref files:
+// <Cube><Cube time="2024-01-02"><Cube currency="USD" rate="1.0956"/>...
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// LoadECB reads the euro foreign exchange reference rates published by the
+// European Central Bank (eurofxref-daily.xml or eurofxref-hist.xml).
+func LoadECB(r io.Reader) (*Rates, error) {
+	envelope := ecbEnvelope{}
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	rates := NewRates("EUR")
+	for _, day := range envelope.Days {
+		t, err := time.Parse("2006-01-02", day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("fx: invalid date %q", day.Time)
+		}
+		for _, rate := range day.Rates {
+			if err := rates.Set(t, rate.Currency, rate.Rate); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(rates.days) == 0 {
+		return nil, fmt.Errorf("fx: no rates found")
+	}
+	return rates, nil
+}
+
+// LoadCSV reads rates from CSV rows of "date,currency,rate", e.g.
+// "2024-01-02,USD,1.0956". The rates are quoted against EUR unless a
+// "# base: XXX" line comes first. A header row starting with "date" is skipped.
+func LoadCSV(r io.Reader) (*Rates, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	base := "EUR"
+	text := string(data)
+	if first, _, _ := strings.Cut(text, "\n"); strings.HasPrefix(strings.ToLower(strings.TrimSpace(first)), "# base:") {
+		base = strings.ToUpper(strings.TrimSpace(first[strings.Index(first, ":")+1:]))
+	}
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	rates := NewRates(base)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(record[0], "date") {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("fx: invalid date %q", record[0])
+		}
+		currency := strings.ToUpper(strings.TrimSpace(record[1]))
+		if err := rates.Set(t, currency, strings.TrimSpace(record[2])); err != nil {
+			return nil, err
+		}
+	}
+	if len(rates.days) == 0 {
+		return nil, fmt.Errorf("fx: no rates found")
+	}
+	return rates, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"isbnAPI/aspen"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// syncBookHistory returns the reading history of the account. When the store
+// is enabled only the pages and records that are not stored yet are fetched;
+// otherwise the whole history is scraped.
+func syncBookHistory(a *Account) []aspen.Book {
+	if db == nil {
+		return readBookHistoryList(a.client)
+	}
+
+	a.syncMu.Lock()
+	err := syncStore(a)
+	a.syncMu.Unlock()
+	if err != nil {
+		fmt.Printf("Error syncing the reading history of %s: %v\n", a.ID, err)
+	}
+	books, err := db.Books(a.ID)
+	if err != nil {
+		fmt.Println("Error reading the store:", err)
+		return nil
+	}
+	return books
+}
+
+// syncStore walks the reading history from the most recent page and stores
+// the books it does not know yet. Once a full sync has completed it stops at
+// the first page without new books, since the older pages are stored already.
+func syncStore(a *Account) error {
+	synced, err := db.Synced(a.ID)
+	if err != nil {
+		return err
+	}
+	paginationTotal, err := extractTotalPagination(a.client)
+	if err != nil {
+		return err
+	}
+
+	var newBooks, knownBooks []aspen.Book
+	complete := true
+	for page := 1; page <= paginationTotal; page++ {
+		res, err := a.client.ReadingHistoryPage(page)
+		if err != nil {
+			return err
+		}
+		if !res.Success || len(res.Titles) == 0 {
+			break
+		}
+		pageHasNew := false
+		for _, t := range res.Titles {
+			stored, found, err := db.Book(a.ID, t.PermanentId)
+			if err != nil {
+				return err
+			}
+			if !found {
+				newBooks = append(newBooks, t)
+				pageHasNew = true
+				continue
+			}
+			// Refresh the checkout dates of known books, keeping the
+			// details read from their record page
+			t.ISBN, t.ListPrice = stored.ISBN, stored.ListPrice
+			if t.Title == "" {
+				t.Title = stored.Title
+			}
+			knownBooks = append(knownBooks, t)
+		}
+		if synced && !pageHasNew {
+			break
+		}
+	}
+
+	// Only store the books whose record page could be read, the others are
+	// picked up again by the next sync
+	books, fetched := addBookDetails(a.client, newBooks)
+	stored := make([]aspen.Book, 0, len(books))
+	for _, book := range books {
+		if fetched[book.PermanentId] {
+			stored = append(stored, book)
+		} else {
+			complete = false
+		}
+	}
+	if err := db.AddBooks(a.ID, knownBooks); err != nil {
+		return err
+	}
+	if err := db.AddBooks(a.ID, stored); err != nil {
+		return err
+	}
+	if !synced && complete {
+		return db.MarkSynced(a.ID)
+	}
+	return nil
+}
+
+// filterHistory applies the query parameters of /history/ to the books:
+// from and to (YYYY-MM-DD) keep the books checked out in that range, format
+// keeps one format, and sort orders by "checkout", "-checkout", "title" or
+// "author".
+func filterHistory(books []aspen.Book, query url.Values) ([]aspen.Book, error) {
+	var from, to time.Time
+	var err error
+	if v := query.Get("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, fmt.Errorf("invalid from date %q, want YYYY-MM-DD", v)
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return nil, fmt.Errorf("invalid to date %q, want YYYY-MM-DD", v)
+		}
+		// to is inclusive
+		to = to.AddDate(0, 0, 1)
+	}
+	format := query.Get("format")
+
+	filtered := make([]aspen.Book, 0, len(books))
+	for _, book := range books {
+		checkout := book.LastCheckedOut()
+		if !from.IsZero() && checkout.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !checkout.Before(to) {
+			continue
+		}
+		if format != "" && !strings.EqualFold(book.Format, format) {
+			continue
+		}
+		filtered = append(filtered, book)
+	}
+
+	switch query.Get("sort") {
+	case "":
+	case "checkout":
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].LastCheckedOut().Before(filtered[j].LastCheckedOut())
+		})
+	case "-checkout":
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].LastCheckedOut().After(filtered[j].LastCheckedOut())
+		})
+	case "title":
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return strings.ToLower(filtered[i].Title) < strings.ToLower(filtered[j].Title)
+		})
+	case "author":
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return strings.ToLower(filtered[i].Author) < strings.ToLower(filtered[j].Author)
+		})
+	default:
+		return nil, fmt.Errorf("invalid sort %q, want checkout, -checkout, title or author", query.Get("sort"))
+	}
+	return filtered, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"isbnAPI/aspen"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// holdsHandler serves the holds of an account:
+//
+//	GET  /holds/                 lists the available and pending holds
+//	POST /holds/                 places a hold on recordId for pickupLocation
+//	POST /holds/{id}/freeze      freezes a hold, until the optional reactivate date
+//	POST /holds/{id}/thaw        thaws a frozen hold
+//	POST /holds/{id}/pickup      moves a hold to location
+//	POST /holds/{id}/cancel      cancels a hold
+//
+// The arguments are read from the query string or a form body.
+func holdsHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/holds/"), "/")
+	if path == "" && r.Method == http.MethodGet {
+		listHolds(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "use POST to change holds")
+		return
+	}
+
+	var call func(c *aspen.Client) (aspen.HoldResult, error)
+	id, action, _ := strings.Cut(path, "/")
+	switch action {
+	case "":
+		if path != "" {
+			writeJSONError(w, http.StatusNotFound, "unknown hold action")
+			return
+		}
+		recordId, location := r.FormValue("recordId"), r.FormValue("pickupLocation")
+		if recordId == "" || location == "" {
+			writeJSONError(w, http.StatusBadRequest, "recordId and pickupLocation are required")
+			return
+		}
+		call = func(c *aspen.Client) (aspen.HoldResult, error) {
+			return c.PlaceHold(recordId, location)
+		}
+	case "freeze":
+		var reactivate time.Time
+		if v := r.FormValue("reactivate"); v != "" {
+			var err error
+			if reactivate, err = time.Parse("2006-01-02", v); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "invalid reactivate date "+v+", want YYYY-MM-DD")
+				return
+			}
+		}
+		call = func(c *aspen.Client) (aspen.HoldResult, error) {
+			return c.FreezeHold(id, reactivate)
+		}
+	case "thaw":
+		call = func(c *aspen.Client) (aspen.HoldResult, error) {
+			return c.ThawHold(id)
+		}
+	case "pickup":
+		location := r.FormValue("location")
+		if location == "" {
+			writeJSONError(w, http.StatusBadRequest, "location is required")
+			return
+		}
+		call = func(c *aspen.Client) (aspen.HoldResult, error) {
+			return c.ChangePickupLocation(id, location)
+		}
+	case "cancel":
+		call = func(c *aspen.Client) (aspen.HoldResult, error) {
+			return c.CancelHold(id)
+		}
+	default:
+		writeJSONError(w, http.StatusNotFound, "unknown hold action "+action)
+		return
+	}
+
+	account := accountFor(w, r)
+	if account == nil {
+		return
+	}
+	result, err := call(account.client)
+	if errors.Is(err, aspen.ErrHoldNotFound) {
+		writeJSONError(w, http.StatusNotFound, "no hold with id "+id)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// listHolds writes the holds of the account, split into the ones waiting
+// for pickup and the pending ones
+func listHolds(w http.ResponseWriter, r *http.Request) {
+	account := accountFor(w, r)
+	if account == nil {
+		return
+	}
+	holds, err := account.client.Holds()
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	available := make([]aspen.Hold, 0)
+	pending := make([]aspen.Hold, 0)
+	for _, hold := range holds {
+		if hold.Available {
+			available = append(available, hold)
+		} else {
+			pending = append(pending, hold)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":   "Holds",
+		"available": available,
+		"pending":   pending,
+	})
+}
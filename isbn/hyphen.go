@@ -0,0 +1,96 @@
+package isbn
+
+import "strconv"
+
+// registrantRange gives the registrant length for the 7 digits that follow
+// the registration group, as published in the ISBN International RangeMessage.
+type registrantRange struct {
+	lo, hi int
+	length int
+}
+
+// groups maps EAN prefix and registration group to the registrant ranges.
+// Only the largest groups are listed; ISBNs of other groups are not hyphenated.
+var groups = map[string][]registrantRange{
+	// English language
+	"978-0": {
+		{0, 1999999, 2}, {2000000, 2279999, 3}, {2280000, 2289999, 4},
+		{2290000, 3689999, 3}, {3690000, 3699999, 4}, {3700000, 6389999, 3},
+		{6390000, 6397999, 4}, {6398000, 6399999, 7}, {6400000, 6449999, 3},
+		{6450000, 6459999, 7}, {6460000, 6479999, 3}, {6480000, 6489999, 7},
+		{6490000, 6549999, 3}, {6550000, 6559999, 4}, {6560000, 6999999, 3},
+		{7000000, 8499999, 4}, {8500000, 8999999, 5}, {9000000, 9499999, 6},
+		{9500000, 9999999, 7},
+	},
+	"978-1": {
+		{0, 999999, 2}, {1000000, 3999999, 3}, {4000000, 5499999, 4},
+		{5500000, 8697999, 5}, {8698000, 9989999, 6}, {9990000, 9999999, 7},
+	},
+	// French language
+	"978-2": {
+		{0, 1999999, 2}, {2000000, 3499999, 3}, {3500000, 3999999, 5},
+		{4000000, 6999999, 3}, {7000000, 8399999, 4}, {8400000, 8999999, 5},
+		{9000000, 9499999, 6}, {9500000, 9999999, 7},
+	},
+	// German language
+	"978-3": {
+		{0, 299999, 2}, {300000, 339999, 3}, {340000, 369999, 4},
+		{370000, 399999, 5}, {400000, 1999999, 2}, {2000000, 6999999, 3},
+		{7000000, 8499999, 4}, {8500000, 8999999, 5}, {9000000, 9499999, 6},
+		{9500000, 9539999, 7}, {9540000, 9699999, 5}, {9700000, 9849999, 7},
+		{9850000, 9999999, 5},
+	},
+	// Japan
+	"978-4": {
+		{0, 1999999, 2}, {2000000, 6999999, 3}, {7000000, 8499999, 4},
+		{8500000, 8999999, 5}, {9000000, 9499999, 6}, {9500000, 9999999, 7},
+	},
+	// France
+	"979-10": {
+		{0, 1999999, 2}, {2000000, 6999999, 3}, {7000000, 8999999, 4},
+		{9000000, 9759999, 5}, {9760000, 9999999, 6},
+	},
+}
+
+// Hyphenate returns the ISBN with hyphens between the prefix, registration
+// group, registrant, publication and check digit, e.g. 978-0-306-40615-7.
+// ISBNs of groups without known ranges are returned without hyphens, and
+// the zero ISBN as "".
+func (i ISBN) Hyphenate() string {
+	if i.digits == "" {
+		return ""
+	}
+	d := i.To13().digits
+	prefix := d[:3]
+	for groupLen := 1; groupLen <= 5; groupLen++ {
+		group := d[3 : 3+groupLen]
+		ranges, ok := groups[prefix+"-"+group]
+		if !ok {
+			continue
+		}
+		rest := d[3+groupLen : 12]
+		window := rest
+		if len(window) > 7 {
+			window = window[:7]
+		}
+		for len(window) < 7 {
+			window += "0"
+		}
+		n, _ := strconv.Atoi(window)
+		for _, r := range ranges {
+			if n < r.lo || n > r.hi {
+				continue
+			}
+			if r.length >= len(rest) {
+				break
+			}
+			parts := group + "-" + rest[:r.length] + "-" + rest[r.length:] + "-"
+			if i.Is10() {
+				return parts + i.digits[9:]
+			}
+			return prefix + "-" + parts + d[12:]
+		}
+		break
+	}
+	return i.digits
+}
@@ -0,0 +1,222 @@
+// Package isbn parses, validates and converts International Standard Book Numbers.
+package isbn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ISBN is a valid ISBN-10 or ISBN-13. The zero value is not a valid ISBN.
+type ISBN struct {
+	// digits holds the 10 or 13 characters without separators, X in upper case
+	digits string
+}
+
+// ErrInvalid matches every validation error with errors.Is
+var ErrInvalid = errors.New("isbn: invalid ISBN")
+
+// ErrNotConvertible is returned by To10 for ISBN-13s outside the 978 prefix
+var ErrNotConvertible = errors.New("isbn: only 978 ISBN-13s have an ISBN-10 form")
+
+// Rule is a check an ISBN has to pass
+type Rule int
+
+const (
+	// RuleLength requires 10 or 13 characters once the separators are removed
+	RuleLength Rule = iota + 1
+	// RuleCharacter allows only digits, and X as the last character of an ISBN-10
+	RuleCharacter
+	// RuleMisplacedX rejects an X anywhere but the check digit of an ISBN-10
+	RuleMisplacedX
+	// RuleChecksum requires the check digit to match the other digits
+	RuleChecksum
+)
+
+func (r Rule) String() string {
+	switch r {
+	case RuleLength:
+		return "length"
+	case RuleCharacter:
+		return "illegal character"
+	case RuleMisplacedX:
+		return "misplaced X"
+	case RuleChecksum:
+		return "checksum"
+	}
+	return "unknown rule"
+}
+
+// Error tells which rule an ISBN failed
+type Error struct {
+	// Input is the ISBN as validated, without prefix and separators
+	Input string
+	Rule  Rule
+	// Pos is the index of the offending character for RuleCharacter and RuleMisplacedX
+	Pos int
+}
+
+func (e *Error) Error() string {
+	switch e.Rule {
+	case RuleLength:
+		return fmt.Sprintf("isbn: %q has %d characters, want 10 or 13", e.Input, len(e.Input))
+	case RuleCharacter:
+		return fmt.Sprintf("isbn: %q has illegal character %q at position %d", e.Input, e.Input[e.Pos], e.Pos+1)
+	case RuleMisplacedX:
+		return fmt.Sprintf("isbn: %q has X at position %d, only allowed as the ISBN-10 check digit", e.Input, e.Pos+1)
+	case RuleChecksum:
+		return fmt.Sprintf("isbn: %q has a wrong check digit", e.Input)
+	}
+	return "isbn: invalid ISBN " + strconv.Quote(e.Input)
+}
+
+// Is makes errors.Is(err, ErrInvalid) true for every Error
+func (e *Error) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+// prefixes are stripped by Parse, longest first
+var prefixes = []string{"ISBN-13", "ISBN-10", "ISBN13", "ISBN10", "ISBN"}
+
+// Parse reads an ISBN-10 or ISBN-13. It accepts hyphens and spaces between
+// the digits, a lowercase x check digit and an "ISBN:", "ISBN-13:" or
+// similar prefix. Invalid input is reported with an *Error.
+func Parse(s string) (ISBN, error) {
+	s = strings.TrimSpace(s)
+	upper := strings.ToUpper(s)
+	for _, p := range prefixes {
+		if strings.HasPrefix(upper, p) {
+			s = strings.TrimLeft(s[len(p):], ": ")
+			break
+		}
+	}
+	s = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+
+	var err error
+	switch len(s) {
+	case 10:
+		err = Validate10(s)
+	default:
+		err = Validate13(s)
+	}
+	if err != nil {
+		return ISBN{}, err
+	}
+	return ISBN{digits: s}, nil
+}
+
+// MustParse is like Parse but panics on invalid input
+func MustParse(s string) ISBN {
+	i, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// String returns the ISBN without separators
+func (i ISBN) String() string {
+	return i.digits
+}
+
+// Is10 reports whether the ISBN is in the 10 digit form
+func (i ISBN) Is10() bool {
+	return len(i.digits) == 10
+}
+
+// To13 returns the ISBN-13 form of the ISBN
+func (i ISBN) To13() ISBN {
+	if !i.Is10() {
+		return i
+	}
+	body := "978" + i.digits[:9]
+	return ISBN{digits: body + string(checkDigit13(body))}
+}
+
+// To10 returns the ISBN-10 form of the ISBN. Only ISBN-13s starting with 978
+// have one.
+func (i ISBN) To10() (ISBN, error) {
+	if i.Is10() {
+		return i, nil
+	}
+	if !strings.HasPrefix(i.digits, "978") {
+		return ISBN{}, ErrNotConvertible
+	}
+	body := i.digits[3:12]
+	return ISBN{digits: body + string(checkDigit10(body))}, nil
+}
+
+// Validate10 checks that s is an ISBN-10 without separators: 9 digits and a
+// check digit that is a digit or an upper case X. The returned error is an *Error.
+func Validate10(s string) error {
+	if len(s) != 10 {
+		return &Error{Input: s, Rule: RuleLength}
+	}
+	for i := 0; i < 10; i++ {
+		c := s[i]
+		switch {
+		case isDigit(c):
+		case c == 'X' && i == 9:
+		case c == 'X':
+			return &Error{Input: s, Rule: RuleMisplacedX, Pos: i}
+		default:
+			return &Error{Input: s, Rule: RuleCharacter, Pos: i}
+		}
+	}
+	if checkDigit10(s[:9]) != s[9] {
+		return &Error{Input: s, Rule: RuleChecksum}
+	}
+	return nil
+}
+
+// Validate13 checks that s is an ISBN-13 without separators: 13 digits with a
+// valid check digit. The returned error is an *Error.
+func Validate13(s string) error {
+	if len(s) != 13 {
+		return &Error{Input: s, Rule: RuleLength}
+	}
+	for i := 0; i < 13; i++ {
+		switch c := s[i]; {
+		case isDigit(c):
+		case c == 'X':
+			return &Error{Input: s, Rule: RuleMisplacedX, Pos: i}
+		default:
+			return &Error{Input: s, Rule: RuleCharacter, Pos: i}
+		}
+	}
+	if checkDigit13(s[:12]) != s[12] {
+		return &Error{Input: s, Rule: RuleChecksum}
+	}
+	return nil
+}
+
+// checkDigit10 computes the check digit of the first 9 digits of an ISBN-10
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// checkDigit13 computes the check digit of the first 12 digits of an ISBN-13
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		if i%2 == 0 {
+			sum += int(body[i] - '0')
+		} else {
+			sum += 3 * int(body[i]-'0')
+		}
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,203 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate10(t *testing.T) {
+	tests := []struct {
+		in   string
+		rule Rule
+		pos  int
+	}{
+		{in: "0306406152"},
+		{in: "080442957X"},
+		{in: "030640615", rule: RuleLength},
+		{in: "03064061521", rule: RuleLength},
+		{in: "12345678:0", rule: RuleCharacter, pos: 8},
+		{in: "03064O6152", rule: RuleCharacter, pos: 5},
+		{in: "080442957x", rule: RuleCharacter, pos: 9},
+		{in: "03064X6152", rule: RuleMisplacedX, pos: 5},
+		{in: "0306406153", rule: RuleChecksum},
+	}
+	for _, tt := range tests {
+		checkRule(t, "Validate10", tt.in, Validate10(tt.in), tt.rule, tt.pos)
+	}
+}
+
+func TestValidate13(t *testing.T) {
+	tests := []struct {
+		in   string
+		rule Rule
+		pos  int
+	}{
+		{in: "9780306406157"},
+		{in: "9791090636071"},
+		{in: "978030640615", rule: RuleLength},
+		{in: "0306406152", rule: RuleLength},
+		{in: "97803064:6157", rule: RuleCharacter, pos: 8},
+		{in: "978030640615X", rule: RuleMisplacedX, pos: 12},
+		{in: "9780306406158", rule: RuleChecksum},
+	}
+	for _, tt := range tests {
+		checkRule(t, "Validate13", tt.in, Validate13(tt.in), tt.rule, tt.pos)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		rule Rule
+	}{
+		{in: "0306406152", want: "0306406152"},
+		{in: "9780306406157", want: "9780306406157"},
+		{in: "080442957x", want: "080442957X"},
+		{in: "0-306-40615-2", want: "0306406152"},
+		{in: "978-0-306-40615-7", want: "9780306406157"},
+		{in: "978 0 306 40615 7", want: "9780306406157"},
+		{in: "  0306406152  ", want: "0306406152"},
+		{in: "ISBN: 0-306-40615-2", want: "0306406152"},
+		{in: "ISBN 978-0-306-40615-7", want: "9780306406157"},
+		{in: "ISBN-10: 0306406152", want: "0306406152"},
+		{in: "ISBN-13: 978-0-306-40615-7", want: "9780306406157"},
+		{in: "isbn:9780306406157", want: "9780306406157"},
+		{in: "", rule: RuleLength},
+		{in: "ISBN:", rule: RuleLength},
+		{in: "030640615", rule: RuleLength},
+		{in: "12345678:0", rule: RuleCharacter},
+		{in: "0-306-4O615-2", rule: RuleCharacter},
+		{in: "03064X6152", rule: RuleMisplacedX},
+		{in: "978-0-306-40615-X", rule: RuleMisplacedX},
+		{in: "0-306-40615-3", rule: RuleChecksum},
+		{in: "978-0-306-40615-8", rule: RuleChecksum},
+	}
+	for _, tt := range tests {
+		i, err := Parse(tt.in)
+		if tt.rule != 0 {
+			checkRule(t, "Parse", tt.in, err, tt.rule, -1)
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.in, err)
+			continue
+		}
+		if i.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, i, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		isbn10, isbn13 string
+	}{
+		{"0306406152", "9780306406157"},
+		{"080442957X", "9780804429573"},
+		{"0316769487", "9780316769488"},
+	}
+	for _, tt := range tests {
+		i10, i13 := MustParse(tt.isbn10), MustParse(tt.isbn13)
+		if got := i10.To13(); got != i13 {
+			t.Errorf("%s.To13() = %s, want %s", i10, got, i13)
+		}
+		if got := i13.To13(); got != i13 {
+			t.Errorf("%s.To13() = %s, want %s", i13, got, i13)
+		}
+		got, err := i13.To10()
+		if err != nil || got != i10 {
+			t.Errorf("%s.To10() = %s, %v, want %s", i13, got, err, i10)
+		}
+		if got, err := i10.To10(); err != nil || got != i10 {
+			t.Errorf("%s.To10() = %s, %v, want %s", i10, got, err, i10)
+		}
+	}
+
+	if _, err := MustParse("9791090636071").To10(); !errors.Is(err, ErrNotConvertible) {
+		t.Errorf("To10 of a 979 ISBN: got %v, want ErrNotConvertible", err)
+	}
+}
+
+func TestHyphenate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"9780306406157", "978-0-306-40615-7"},
+		{"0306406152", "0-306-40615-2"},
+		{"080442957X", "0-8044-2957-X"},
+		{"9780228012306", "978-0-2280-1230-6"},
+		{"9780639800103", "978-0-6398001-0-3"},
+		{"9781593279509", "978-1-59327-950-9"},
+		{"9782070360024", "978-2-07-036002-4"},
+		{"9783161484100", "978-3-16-148410-0"},
+		{"9784101010014", "978-4-10-101001-4"},
+		{"9791090636071", "979-10-90636-07-1"},
+		// Spanish group, which has no range table
+		{"9788420412146", "9788420412146"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.in).Hyphenate(); got != tt.want {
+			t.Errorf("%s.Hyphenate() = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	if got := (ISBN{}).Hyphenate(); got != "" {
+		t.Errorf("zero ISBN Hyphenate() = %q, want \"\"", got)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"0306406152", "080442957x", "978-0-306-40615-7", "ISBN: 0-306-40615-2",
+		"ISBN-13:9791090636071", "12345678:0", "03064X6152", "", "ISBN",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		i, err := Parse(s)
+		if err != nil {
+			var isbnErr *Error
+			if !errors.As(err, &isbnErr) || !errors.Is(err, ErrInvalid) {
+				t.Fatalf("Parse(%q) error %v is not an *Error", s, err)
+			}
+			return
+		}
+		again, err := Parse(i.String())
+		if err != nil || again != i {
+			t.Fatalf("Parse(%q) = %s, but Parse(%q) = %s, %v", s, i, i.String(), again, err)
+		}
+		if i.Is10() {
+			if back, err := i.To13().To10(); err != nil || back != i {
+				t.Fatalf("%s.To13().To10() = %s, %v", i, back, err)
+			}
+		}
+		i.Hyphenate()
+	})
+}
+
+// checkRule checks that err is an *Error for rule, or nil when rule is 0.
+// pos is checked unless it is -1.
+func checkRule(t *testing.T, name, in string, err error, rule Rule, pos int) {
+	t.Helper()
+	if rule == 0 {
+		if err != nil {
+			t.Errorf("%s(%q) error: %v", name, in, err)
+		}
+		return
+	}
+	var isbnErr *Error
+	if !errors.As(err, &isbnErr) {
+		t.Errorf("%s(%q) = %v, want %s error", name, in, err, rule)
+		return
+	}
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("%s(%q) error does not match ErrInvalid", name, in)
+	}
+	if isbnErr.Rule != rule {
+		t.Errorf("%s(%q) failed rule %s, want %s", name, in, isbnErr.Rule, rule)
+	}
+	if pos >= 0 && (rule == RuleCharacter || rule == RuleMisplacedX) && isbnErr.Pos != pos {
+		t.Errorf("%s(%q) failed at position %d, want %d", name, in, isbnErr.Pos, pos)
+	}
+}
@@ -4,31 +4,64 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"isbnAPI/aspen"
+	"isbnAPI/cache"
+	"isbnAPI/fx"
+	"isbnAPI/isbn"
+	"isbnAPI/metadata"
+	"isbnAPI/money"
+	"isbnAPI/store"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
 var (
-	sessionCookie   string
-	totalPagination int
+	config Config
+	// db keeps the reading history on disk. It is nil when the store cannot be opened
+	db *store.Store
+	// isbnCache caches the ISBN lookups on disk. It is nil when the cache cannot be opened
+	isbnCache *cache.Cache
+	// metadataProvider looks up the book metadata and list prices
+	metadataProvider metadata.Provider
+	// rates converts the savings to the home currency. It is nil when no rates file is configured
+	rates *fx.Rates
 )
 
 func init() {
-	result, cookie := login()
-	if result {
-		sessionCookie = cookie
-		fmt.Println("Login successfully")
-		// Get the total pagination
-		var err error
-		totalPagination, err = extractTotalPagination(sessionCookie)
+	var err error
+	config, err = loadConfig()
+	if err != nil {
+		panic(err)
+	}
+	metadataProvider, err = newMetadataProvider(config)
+	if err != nil {
+		panic(err)
+	}
+	if config.RatesFile != "" {
+		rates, err = fx.Load(config.RatesFile)
 		if err != nil {
-			panic(errors.New("cannot get total pagination"))
+			panic(err)
 		}
-	} else {
-		panic("Login failed")
 	}
-
+	db, err = store.Open(config.StoreFile)
+	if err != nil {
+		fmt.Println("Error opening the store, the history will not be cached:", err)
+		db = nil
+	}
+	isbnCache, err = cache.Open(config.ISBNCacheFile, config.ISBNCacheTTL)
+	if err != nil {
+		fmt.Println("Error opening the ISBN cache, lookups will not be cached:", err)
+		isbnCache = nil
+	}
+	for _, ac := range config.Accounts {
+		a := newAccount(config, ac)
+		accounts[a.ID] = a
+		accountIDs = append(accountIDs, a.ID)
+		// Log in in the background so /isbn/ is served even when the library is down
+		go a.connectLoop()
+	}
 }
 
 func main() {
@@ -40,12 +73,38 @@ func main() {
 	})
 	// register a handler for the /isbn/ route
 	mux.HandleFunc("/isbn/", func(w http.ResponseWriter, r *http.Request) {
-		// get the isbn from the URL
-		isbn := r.URL.Path[len("/isbn/"):]
+		// get the isbn from the URL and check it before asking the providers
+		raw := r.URL.Path[len("/isbn/"):]
+		i, err := isbn.Parse(raw)
+		if err != nil {
+			response := map[string]interface{}{
+				"error":   http.StatusText(http.StatusBadRequest),
+				"message": err.Error(),
+				"isbn":    raw,
+			}
+			var isbnErr *isbn.Error
+			if errors.As(err, &isbnErr) {
+				response["rule"] = isbnErr.Rule.String()
+			}
+			writeJSON(w, http.StatusBadRequest, response)
+			return
+		}
 		// get the book price and other items
-		res := ISBNContent(isbn)
+		m, err := ISBNContent(i.String())
+		if errors.Is(err, metadata.ErrNotFound) {
+			writeJSONError(w, http.StatusNotFound, "no metadata found for ISBN "+i.String())
+			return
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		var res interface{} = m
+		// the map keyed by isbndb labels is kept for older clients
+		if config.ISBNLegacyJSON || r.URL.Query().Get("format") == "legacy" {
+			res = m.Legacy()
+		}
 
-		// convert the map to JSON
 		resJson, err := json.MarshalIndent(res, "", "  ")
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -54,10 +113,50 @@ func main() {
 		w.Header().Set("Content-Type", "application/json")
 		w.Write(resJson)
 	})
+	// List the linked library accounts
+	mux.HandleFunc("/accounts/", func(w http.ResponseWriter, r *http.Request) {
+		statuses := make([]map[string]interface{}, len(accountIDs))
+		for i, id := range accountIDs {
+			statuses[i] = accounts[id].status()
+		}
+		resJson, err := json.MarshalIndent(map[string]interface{}{
+			"default":  accountIDs[0],
+			"accounts": statuses,
+		}, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resJson)
+	})
+
+	// Show the ISBN cache counters
+	mux.HandleFunc("/cache/", func(w http.ResponseWriter, r *http.Request) {
+		if isbnCache == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "the ISBN cache is disabled")
+			return
+		}
+		resJson, err := json.MarshalIndent(isbnCache.Stats(), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resJson)
+	})
+
 	// Get the history of checked out books
 	mux.HandleFunc("/history/", func(w http.ResponseWriter, r *http.Request) {
-		// get the pagination from the URL
-		books := readBookHistoryList(sessionCookie, totalPagination)
+		account := accountFor(w, r)
+		if account == nil {
+			return
+		}
+		books, err := filterHistory(syncBookHistory(account), r.URL.Query())
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		// convert the map to JSON
 		resJson, err := json.MarshalIndent(books, "", "  ")
 		if err != nil {
@@ -69,16 +168,27 @@ func main() {
 
 	// Get total savings
 	mux.HandleFunc("/savings/", func(w http.ResponseWriter, r *http.Request) {
-		// get the pagination from the URL
+		account := accountFor(w, r)
+		if account == nil {
+			return
+		}
 		startTime := time.Now()
 
-		total := calculateTotalSavings(sessionCookie, totalPagination)
+		books := syncBookHistory(account)
+		totals := calculateTotalSavings2(books)
+		converted, unconverted := convertSavings(books)
+		fines, unconvertedFines := convertFines(finesOf(account))
 		endTime := time.Now()
 		totalTime := endTime.Sub(startTime)
 		var response = map[string]interface{}{
-			"message": "Total savings in USD",
-			"total":   fmt.Sprintf("$%.2f", total),
-			"time":    fmt.Sprintf("%vs", totalTime),
+			"message":          "Total savings in " + config.HomeCurrency,
+			"total":            formatTotal(converted),
+			"totals":           totals.Sorted(),
+			"unconverted":      unconverted,
+			"fines":            formatTotal(fines),
+			"unconvertedFines": unconvertedFines,
+			"net":              formatTotal(netSavings(converted, fines)),
+			"time":             fmt.Sprintf("%vs", totalTime),
 		}
 		// convert the map to JSON
 		resJson, err := json.MarshalIndent(response, "", "  ")
@@ -89,9 +199,50 @@ func main() {
 		w.Write(resJson)
 	})
 
-	// Get the checked out books
+	// Break the savings down by checkout date, format and author
+	mux.HandleFunc("/savings/report/", func(w http.ResponseWriter, r *http.Request) {
+		account := accountFor(w, r)
+		if account == nil {
+			return
+		}
+		report := buildSavingsReport(syncBookHistory(account), finesOf(account))
+		writeJSON(w, http.StatusOK, report)
+	})
+
+	// Get the checked out books, or renew one with POST /due/{id}/renew
 	mux.HandleFunc("/due/", func(w http.ResponseWriter, r *http.Request) {
-		books, _ := checkedOutBooks(sessionCookie)
+		if id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/due/"), "/renew"); ok {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", http.MethodPost)
+				writeJSONError(w, http.StatusMethodNotAllowed, "use POST to renew a checkout")
+				return
+			}
+			account := accountFor(w, r)
+			if account == nil {
+				return
+			}
+			renewal, err := account.client.Renew(id)
+			if errors.Is(err, aspen.ErrCheckoutNotFound) {
+				writeJSONError(w, http.StatusNotFound, "no checkout with id "+id)
+				return
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadGateway, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, renewal)
+			return
+		}
+
+		account := accountFor(w, r)
+		if account == nil {
+			return
+		}
+		books, err := checkedOutBooks(account.client)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
 		response := map[string]interface{}{
 			"message": "Checked-out books",
 			"books":   books,
@@ -108,5 +259,75 @@ func main() {
 		w.Write(jsonResponse)
 	})
 
+	// Renew every checked out book
+	mux.HandleFunc("/due/renew-all", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSONError(w, http.StatusMethodNotAllowed, "use POST to renew the checkouts")
+			return
+		}
+		account := accountFor(w, r)
+		if account == nil {
+			return
+		}
+		renewals, err := account.client.RenewAll()
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		renewed := 0
+		for _, renewal := range renewals {
+			if renewal.Success {
+				renewed++
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"message":  fmt.Sprintf("Renewed %d of %d checkouts", renewed, len(renewals)),
+			"renewals": renewals,
+		})
+	})
+
+	// Show the patron's profile
+	mux.HandleFunc("/me/", func(w http.ResponseWriter, r *http.Request) {
+		account := accountFor(w, r)
+		if account == nil {
+			return
+		}
+		// The login details are known even when the account pages cannot be read
+		profile, err := account.client.Profile()
+		if err != nil {
+			fmt.Printf("Error reading the profile of %s: %v\n", account.ID, err)
+		}
+		writeJSON(w, http.StatusOK, profile)
+	})
+
+	// List the fines and fees
+	mux.HandleFunc("/fines/", func(w http.ResponseWriter, r *http.Request) {
+		account := accountFor(w, r)
+		if account == nil {
+			return
+		}
+		fines, err := account.client.Fines(config.HomeCurrency)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		totals := money.Totals{}
+		for _, fine := range fines {
+			totals.Add(fine.Outstanding)
+		}
+		if fines == nil {
+			fines = []aspen.Fine{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"message": "Fines and fees",
+			"fines":   fines,
+			"balance": totals.Sorted(),
+		})
+	})
+
+	// List, place and change holds
+	mux.HandleFunc("/holds/", holdsHandler)
+
 	http.ListenAndServe(":8080", mux)
 }
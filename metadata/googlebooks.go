@@ -0,0 +1,132 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"isbnAPI/money"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GoogleBooks reads the Google Books volumes API (/books/v1/volumes?q=isbn:...)
+type GoogleBooks struct {
+	// BaseURL is the root of the API, https://www.googleapis.com by default.
+	// It can point to a local stub.
+	BaseURL string
+	// APIKey is sent as the key parameter when set
+	APIKey string
+	Client *http.Client
+}
+
+type googleBooksPrice struct {
+	Amount       float64 `json:"amount"`
+	CurrencyCode string  `json:"currencyCode"`
+}
+
+type googleBooksVolumes struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		VolumeInfo struct {
+			Title               string   `json:"title"`
+			Subtitle            string   `json:"subtitle"`
+			Authors             []string `json:"authors"`
+			Publisher           string   `json:"publisher"`
+			PublishedDate       string   `json:"publishedDate"`
+			PageCount           int      `json:"pageCount"`
+			Categories          []string `json:"categories"`
+			IndustryIdentifiers []struct {
+				Type       string `json:"type"`
+				Identifier string `json:"identifier"`
+			} `json:"industryIdentifiers"`
+			ImageLinks struct {
+				SmallThumbnail string `json:"smallThumbnail"`
+				Thumbnail      string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+		SaleInfo struct {
+			Country     string            `json:"country"`
+			Saleability string            `json:"saleability"`
+			ListPrice   *googleBooksPrice `json:"listPrice"`
+			RetailPrice *googleBooksPrice `json:"retailPrice"`
+		} `json:"saleInfo"`
+	} `json:"items"`
+}
+
+// NewGoogleBooks returns a provider for the Google Books API at baseURL
+func NewGoogleBooks(baseURL, apiKey string, client *http.Client) *GoogleBooks {
+	return &GoogleBooks{BaseURL: strings.TrimRight(baseURL, "/"), APIKey: apiKey, Client: client}
+}
+
+// Name returns "googlebooks"
+func (p *GoogleBooks) Name() string {
+	return "googlebooks"
+}
+
+// Lookup searches the volumes by ISBN and maps the first match
+func (p *GoogleBooks) Lookup(isbn string) (*BookMetadata, error) {
+	q := url.Values{"q": {"isbn:" + isbn}}
+	if p.APIKey != "" {
+		q.Set("key", p.APIKey)
+	}
+	resp, err := p.Client.Get(p.BaseURL + "/books/v1/volumes?" + q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("googlebooks: %s returned %s", isbn, resp.Status)
+	}
+
+	volumes := googleBooksVolumes{}
+	if err := json.NewDecoder(resp.Body).Decode(&volumes); err != nil {
+		return nil, err
+	}
+	if len(volumes.Items) == 0 {
+		return nil, ErrNotFound
+	}
+
+	item := volumes.Items[0]
+	info := item.VolumeInfo
+	m := &BookMetadata{
+		ISBN:      isbn,
+		Title:     info.Title,
+		Authors:   info.Authors,
+		Publisher: info.Publisher,
+		Pages:     info.PageCount,
+		Subjects:  info.Categories,
+		CoverURL:  info.ImageLinks.Thumbnail,
+		Source:    p.Name(),
+		Fields:    map[string]string{},
+	}
+	if info.Subtitle != "" {
+		m.Title += ": " + info.Subtitle
+	}
+	if m.CoverURL == "" {
+		m.CoverURL = info.ImageLinks.SmallThumbnail
+	}
+	if info.PublishedDate != "" {
+		m.Fields["date published"] = info.PublishedDate
+		m.PublishDate = ParsePublishDate(info.PublishedDate)
+	}
+	for _, id := range info.IndustryIdentifiers {
+		switch id.Type {
+		case "ISBN_10":
+			m.Fields["isbn10"] = id.Identifier
+		case "ISBN_13":
+			m.Fields["isbn13"] = id.Identifier
+		}
+		if (id.Type == "ISBN_10" || id.Type == "ISBN_13") && id.Identifier != isbn {
+			m.RelatedISBNs = append(m.RelatedISBNs, id.Identifier)
+		}
+	}
+	// The list price is only given for volumes sold on Google Play
+	if price := item.SaleInfo.ListPrice; price != nil && price.CurrencyCode != "" {
+		amount := strconv.FormatFloat(price.Amount, 'f', money.Exponent(price.CurrencyCode), 64)
+		if listPrice, err := money.New(amount, price.CurrencyCode); err == nil {
+			m.ListPrice = &listPrice
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"isbnAPI/money"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ISBNdb scrapes the book pages of isbndb.com
+type ISBNdb struct {
+	// BaseURL is the root of the site, https://isbndb.com by default
+	BaseURL string
+	Client  *http.Client
+}
+
+var leadingIntRe = regexp.MustCompile(`^\d+`)
+
+// NewISBNdb returns a provider for the isbndb site at baseURL
+func NewISBNdb(baseURL string, client *http.Client) *ISBNdb {
+	return &ISBNdb{BaseURL: strings.TrimRight(baseURL, "/"), Client: client}
+}
+
+// Name returns "isbndb"
+func (p *ISBNdb) Name() string {
+	return "isbndb"
+}
+
+// Lookup scrapes the book table of the ISBN's page
+func (p *ISBNdb) Lookup(isbn string) (*BookMetadata, error) {
+	resp, err := p.Client.Get(p.BaseURL + "/book/" + isbn)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("isbndb: %s returned %s", isbn, resp.Status)
+	}
+
+	// Parse the page
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	content := make(map[string]string)
+	doc.Find(".book-table .table").Each(func(i int, bookTable *goquery.Selection) {
+		bookTable.Find("tr").Each(func(i int, tr *goquery.Selection) {
+			val := ""
+			des := tr.Find("th:nth-child(1)").Text()
+			desp := strings.ToLower(strings.TrimSpace(des))
+			switch desp {
+			case "isbn":
+				val = tr.Find("th:nth-child(2)").Text()
+			case "related isbns":
+				relatedISBNs := make([]string, 0)
+				tr.Find("a").Each(func(i int, s *goquery.Selection) {
+					relatedISBNs = append(relatedISBNs, s.Text())
+				})
+				val = strings.Join(relatedISBNs, ",")
+			default:
+				val = tr.Find("td").Text()
+			}
+			content[desp] = strings.TrimSpace(val)
+		})
+	})
+	if len(content) == 0 {
+		return nil, ErrNotFound
+	}
+
+	m := &BookMetadata{
+		ISBN:      isbn,
+		Title:     content["full title"],
+		Publisher: content["publisher"],
+		CoverURL:  doc.Find(".artwork img").AttrOr("src", ""),
+		Source:    p.Name(),
+		Fields:    content,
+	}
+	for _, author := range strings.Split(content["authors"], "\n") {
+		if author = strings.TrimSpace(author); author != "" {
+			m.Authors = append(m.Authors, author)
+		}
+	}
+	// The price reads like "USD $1,299.00" or "GBP £12.99"
+	if price, err := money.Parse(content["list price"]); err == nil {
+		m.ListPrice = &price
+	}
+	m.PublishDate = ParsePublishDate(content["date published"])
+	m.Binding = content["binding"]
+	for _, related := range strings.Split(content["related isbns"], ",") {
+		if related = strings.TrimSpace(related); related != "" {
+			m.RelatedISBNs = append(m.RelatedISBNs, related)
+		}
+	}
+	if pages := leadingIntRe.FindString(content["pages"]); pages != "" {
+		m.Pages, _ = strconv.Atoi(pages)
+	}
+	return m, nil
+}
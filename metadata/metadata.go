@@ -0,0 +1,182 @@
+// Package metadata looks up book metadata by ISBN from external sources.
+package metadata
+
+import (
+	"errors"
+	"isbnAPI/money"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by a provider that does not know the ISBN
+var ErrNotFound = errors.New("metadata: ISBN not found")
+
+// Provider is a source of book metadata, such as a catalog website or API
+type Provider interface {
+	// Name identifies the provider in the configuration
+	Name() string
+	// Lookup returns the metadata of the edition with the given ISBN
+	Lookup(isbn string) (*BookMetadata, error)
+}
+
+// BookMetadata describes an edition of a book
+type BookMetadata struct {
+	ISBN      string   `json:"isbn"`
+	Title     string   `json:"title"`
+	Authors   []string `json:"authors"`
+	Publisher string   `json:"publisher"`
+	// PublishDate is the publication date. Sources that only give the year or
+	// month are read as the first day of that period.
+	PublishDate time.Time `json:"publishDate"`
+	// ListPrice is the publisher's list price, nil when unknown
+	ListPrice *money.Money `json:"listPrice"`
+	// Binding is the form of the edition, e.g. "Paperback"
+	Binding      string   `json:"binding"`
+	Pages        int      `json:"pages"`
+	Subjects     []string `json:"subjects"`
+	RelatedISBNs []string `json:"relatedIsbns"`
+	CoverURL     string   `json:"coverUrl"`
+	// Source is the name of the provider the metadata came from
+	Source string `json:"source"`
+	// Fields holds the raw fields of the source, keyed by lowercased label
+	Fields map[string]string `json:"fields"`
+}
+
+// publishDateLayouts are the date formats used by the providers
+var publishDateLayouts = []string{
+	"2006-01-02", "2006-01", "2006", "January 2, 2006", "Jan 2, 2006",
+	"January 2006", "Jan 2006", "2 January 2006", time.RFC3339,
+}
+
+// ParsePublishDate reads a publication date in one of the formats used by the
+// providers. It returns the zero time when the date is not understood.
+func ParsePublishDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range publishDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Legacy returns the metadata as the map served by the /isbn/ endpoint,
+// keyed like the isbndb book table ("full title", "list price", ...).
+func (m *BookMetadata) Legacy() map[string]string {
+	content := make(map[string]string, len(m.Fields)+11)
+	for k, v := range m.Fields {
+		content[k] = v
+	}
+	setDefault := func(key, value string) {
+		if content[key] == "" && value != "" {
+			content[key] = value
+		}
+	}
+	setDefault("isbn", m.ISBN)
+	setDefault("full title", m.Title)
+	setDefault("authors", strings.Join(m.Authors, ", "))
+	setDefault("publisher", m.Publisher)
+	if !m.PublishDate.IsZero() {
+		setDefault("date published", m.PublishDate.Format("2006-01-02"))
+	}
+	if m.ListPrice != nil {
+		// isbndb prints US prices as "USD $12.99"
+		if m.ListPrice.Currency == "USD" {
+			setDefault("list price", "USD $"+m.ListPrice.Decimal())
+		} else {
+			setDefault("list price", m.ListPrice.String())
+		}
+	}
+	setDefault("binding", m.Binding)
+	setDefault("related isbns", strings.Join(m.RelatedISBNs, ","))
+	if m.Pages > 0 {
+		setDefault("pages", strconv.Itoa(m.Pages))
+	}
+	setDefault("subjects", strings.Join(m.Subjects, ", "))
+	setDefault("cover", m.CoverURL)
+	return content
+}
+
+// fillFrom copies the fields of o that m is missing
+func (m *BookMetadata) fillFrom(o *BookMetadata) {
+	if m.Title == "" {
+		m.Title = o.Title
+	}
+	if len(m.Authors) == 0 {
+		m.Authors = o.Authors
+	}
+	if m.Publisher == "" {
+		m.Publisher = o.Publisher
+	}
+	if m.PublishDate.IsZero() {
+		m.PublishDate = o.PublishDate
+	}
+	if m.ListPrice == nil {
+		m.ListPrice = o.ListPrice
+	}
+	if m.Binding == "" {
+		m.Binding = o.Binding
+	}
+	if len(m.RelatedISBNs) == 0 {
+		m.RelatedISBNs = o.RelatedISBNs
+	}
+	if m.Pages == 0 {
+		m.Pages = o.Pages
+	}
+	if len(m.Subjects) == 0 {
+		m.Subjects = o.Subjects
+	}
+	if m.CoverURL == "" {
+		m.CoverURL = o.CoverURL
+	}
+	if m.Fields == nil {
+		m.Fields = map[string]string{}
+	}
+	for k, v := range o.Fields {
+		if m.Fields[k] == "" {
+			m.Fields[k] = v
+		}
+	}
+}
+
+// Chain is a Provider that asks each of its providers in turn. The first
+// provider that knows the ISBN gives the metadata; when it has no list price
+// the following providers are asked as well and fill in the missing fields.
+type Chain []Provider
+
+// Name returns the names of the providers of the chain
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, p := range c {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Lookup returns the metadata of the ISBN, asking the providers in order until
+// one has a list price. When no provider knows the ISBN it returns the error
+// of the last provider.
+func (c Chain) Lookup(isbn string) (*BookMetadata, error) {
+	var result *BookMetadata
+	err := ErrNotFound
+	for _, p := range c {
+		var m *BookMetadata
+		m, err = p.Lookup(isbn)
+		if err != nil {
+			continue
+		}
+		if result == nil {
+			result = m
+		} else {
+			result.fillFrom(m)
+		}
+		if result.ListPrice != nil {
+			break
+		}
+	}
+	if result == nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OpenLibrary reads the Open Library books API
+// (/api/books?bibkeys=ISBN:...&format=json&jscmd=data)
+type OpenLibrary struct {
+	// BaseURL is the root of the API, https://openlibrary.org by default.
+	// It can point to a local mirror or a test stub.
+	BaseURL string
+	Client  *http.Client
+}
+
+type openLibraryName struct {
+	Name string `json:"name"`
+}
+
+type openLibraryBook struct {
+	Title         string            `json:"title"`
+	Subtitle      string            `json:"subtitle"`
+	Authors       []openLibraryName `json:"authors"`
+	Publishers    []openLibraryName `json:"publishers"`
+	PublishDate   string            `json:"publish_date"`
+	NumberOfPages int               `json:"number_of_pages"`
+	Subjects      []openLibraryName `json:"subjects"`
+	Cover         struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+// NewOpenLibrary returns a provider for the Open Library API at baseURL
+func NewOpenLibrary(baseURL string, client *http.Client) *OpenLibrary {
+	return &OpenLibrary{BaseURL: strings.TrimRight(baseURL, "/"), Client: client}
+}
+
+// Name returns "openlibrary"
+func (p *OpenLibrary) Name() string {
+	return "openlibrary"
+}
+
+// Lookup reads the data of the ISBN from the books API. Open Library has no
+// prices, so ListPrice is always nil.
+func (p *OpenLibrary) Lookup(isbn string) (*BookMetadata, error) {
+	bibkey := "ISBN:" + isbn
+	q := url.Values{
+		"bibkeys": {bibkey},
+		"format":  {"json"},
+		"jscmd":   {"data"},
+	}
+	resp, err := p.Client.Get(p.BaseURL + "/api/books?" + q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("openlibrary: %s returned %s", isbn, resp.Status)
+	}
+
+	// The response is keyed by bibkey and empty when the ISBN is unknown
+	var books map[string]openLibraryBook
+	if err := json.NewDecoder(resp.Body).Decode(&books); err != nil {
+		return nil, err
+	}
+	book, ok := books[bibkey]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	m := &BookMetadata{
+		ISBN:   isbn,
+		Title:  book.Title,
+		Pages:  book.NumberOfPages,
+		Source: p.Name(),
+		Fields: map[string]string{},
+	}
+	if book.Subtitle != "" {
+		m.Title += ": " + book.Subtitle
+	}
+	for _, a := range book.Authors {
+		m.Authors = append(m.Authors, a.Name)
+	}
+	if len(book.Publishers) > 0 {
+		m.Publisher = book.Publishers[0].Name
+	}
+	for _, s := range book.Subjects {
+		m.Subjects = append(m.Subjects, s.Name)
+	}
+	switch {
+	case book.Cover.Large != "":
+		m.CoverURL = book.Cover.Large
+	case book.Cover.Medium != "":
+		m.CoverURL = book.Cover.Medium
+	default:
+		m.CoverURL = book.Cover.Small
+	}
+	if book.PublishDate != "" {
+		m.Fields["publish date"] = book.PublishDate
+		m.PublishDate = ParsePublishDate(book.PublishDate)
+	}
+	return m, nil
+}
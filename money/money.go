@@ -0,0 +1,134 @@
+// Package money represents exact amounts of money in a given currency.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact decimal amount of a currency
+type Money struct {
+	// Units is the amount in the smallest unit of the currency, e.g. cents
+	Units int64
+	// Currency is the ISO 4217 code, e.g. "USD"
+	Currency string
+}
+
+// ErrInvalidAmount is returned for amounts that are not decimal numbers
+var ErrInvalidAmount = errors.New("money: invalid amount")
+
+// exponents lists the currencies whose minor unit is not a hundredth
+var exponents = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KRW": 0, "KWD": 3, "OMR": 3, "TND": 3, "VND": 0,
+}
+
+// Exponent returns the number of decimals of the currency's minor unit
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+// New returns the amount of currency given as a decimal string such as
+// "12.99". Extra decimals are rounded half away from zero.
+func New(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	amount = strings.TrimSpace(amount)
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(amount, "-")
+
+	whole, frac, _ := strings.Cut(amount, ".")
+	if whole == "" && frac == "" {
+		return Money{}, ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	exp := Exponent(currency)
+	roundUp := false
+	if len(frac) > exp {
+		roundUp = frac[exp] >= '5'
+		if !isDigits(frac[exp:]) {
+			return Money{}, ErrInvalidAmount
+		}
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	units, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if roundUp {
+		units++
+	}
+	if negative {
+		units = -units
+	}
+	return Money{Units: units, Currency: currency}, nil
+}
+
+// Decimal returns the amount as a decimal string, e.g. "12.99"
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	units := m.Units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	s := strconv.FormatInt(units, 10)
+	if exp == 0 {
+		return sign + s
+	}
+	if len(s) <= exp {
+		s = strings.Repeat("0", exp-len(s)+1) + s
+	}
+	return sign + s[:len(s)-exp] + "." + s[len(s)-exp:]
+}
+
+// String returns the amount followed by the currency, e.g. "12.99 USD"
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the money as {"amount": "12.99", "currency": "USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON
+func (m *Money) UnmarshalJSON(data []byte) error {
+	v := moneyJSON{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	parsed, err := New(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
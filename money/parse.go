@@ -0,0 +1,109 @@
+package money
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrNoAmount is returned by Parse when the string holds no number
+var ErrNoAmount = errors.New("money: no amount found")
+
+var (
+	codeRe   = regexp.MustCompile(`\b[A-Z]{3}\b`)
+	amountRe = regexp.MustCompile(`\d[\d.,\s]*`)
+)
+
+// currencies lists the ISO 4217 codes Parse accepts, so that words such as
+// "TBD" are not read as a currency
+var currencies = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND
+		BOB BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF
+		DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD
+		HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW
+		KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR
+		MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN
+		PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN
+		SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS VES
+		VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWL`) {
+		currencies[code] = true
+	}
+}
+
+// symbols maps currency symbols to ISO codes, longest symbols first so that
+// "CA$" wins over "$"
+var symbols = []struct {
+	symbol   string
+	currency string
+}{
+	{"US$", "USD"}, {"CA$", "CAD"}, {"AU$", "AUD"}, {"NZ$", "NZD"}, {"HK$", "HKD"},
+	{"C$", "CAD"}, {"A$", "AUD"}, {"R$", "BRL"},
+	{"$", "USD"}, {"£", "GBP"}, {"€", "EUR"}, {"¥", "JPY"}, {"₹", "INR"}, {"₩", "KRW"},
+}
+
+// Parse reads a price as printed by the metadata sources, such as
+// "USD $12.99", "GBP £12.99", "$1,299.00", "12,99 €" or "EUR 12.99".
+// An ISO code takes precedence over a symbol; a bare "$" is read as USD.
+// A minus sign anywhere before the number, as in "-$5.00" or "$-5.00", and
+// accounting parentheses, as in "($5.00)", make the amount negative.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	currency := ""
+	for _, code := range codeRe.FindAllString(s, -1) {
+		if currencies[code] {
+			currency = code
+			break
+		}
+	}
+	if currency == "" {
+		for _, sym := range symbols {
+			if strings.Contains(s, sym.symbol) {
+				currency = sym.currency
+				break
+			}
+		}
+	}
+	if currency == "" {
+		return Money{}, errors.New("money: no currency in " + s)
+	}
+
+	loc := amountRe.FindStringIndex(s)
+	if loc == nil {
+		return Money{}, ErrNoAmount
+	}
+	amount := normalizeAmount(s[loc[0]:loc[1]])
+	before, after := s[:loc[0]], s[loc[1]:]
+	if strings.ContainsAny(before, "-−") || strings.Contains(before, "(") && strings.Contains(after, ")") {
+		amount = "-" + amount
+	}
+	return New(amount, currency)
+}
+
+// normalizeAmount turns an amount with thousands separators and a decimal
+// point or comma into a plain decimal such as "1299.00".
+func normalizeAmount(amount string) string {
+	amount = strings.Join(strings.Fields(amount), "")
+	lastDot := strings.LastIndex(amount, ".")
+	lastComma := strings.LastIndex(amount, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// The separator that comes last is the decimal one
+		if lastDot > lastComma {
+			return strings.ReplaceAll(amount, ",", "")
+		}
+		return strings.Replace(strings.ReplaceAll(amount, ".", ""), ",", ".", 1)
+	case lastComma >= 0:
+		// "1,299" and "1,299,000" group thousands, "12,99" is a decimal comma
+		if strings.Count(amount, ",") > 1 || len(amount)-lastComma-1 == 3 {
+			return strings.ReplaceAll(amount, ",", "")
+		}
+		return strings.Replace(amount, ",", ".", 1)
+	case strings.Count(amount, ".") > 1:
+		// "1.299.000" groups thousands
+		return strings.ReplaceAll(amount, ".", "")
+	}
+	return amount
+}
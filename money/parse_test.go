@@ -0,0 +1,62 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "USD $12.99", want: "12.99 USD"},
+		{in: "GBP £12.99", want: "12.99 GBP"},
+		{in: "EUR 12.99", want: "12.99 EUR"},
+		{in: "$1,299.00", want: "1299.00 USD"},
+		{in: "$1,299", want: "1299.00 USD"},
+		{in: "12,99 €", want: "12.99 EUR"},
+		{in: "1.299,00 €", want: "1299.00 EUR"},
+		{in: "1 299,00 €", want: "1299.00 EUR"},
+		{in: "CA$15.50", want: "15.50 CAD"},
+		{in: "A$20", want: "20.00 AUD"},
+		{in: "¥1,500", want: "1500 JPY"},
+		{in: "  £7.5 ", want: "7.50 GBP"},
+		{in: "12.99 USD", want: "12.99 USD"},
+		{in: "TBD $5", want: "5.00 USD"},
+		{in: "TBD USD 5", want: "5.00 USD"},
+		{in: "-$5.00", want: "-5.00 USD"},
+		{in: "$-5.00", want: "-5.00 USD"},
+		{in: "- $5.00", want: "-5.00 USD"},
+		{in: "USD -5.00", want: "-5.00 USD"},
+		{in: "-5,00 €", want: "-5.00 EUR"},
+		{in: "($5.00)", want: "-5.00 USD"},
+		{in: "(USD 1,299.00)", want: "-1299.00 USD"},
+		{in: "", err: true},
+		{in: "12.99", err: true},
+		{in: "TBD 12.99", err: true},
+		{in: "USD", err: true},
+		{in: "$", err: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Parse(%q) = %s, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := Parse("USD"); !errors.Is(err, ErrNoAmount) {
+		t.Errorf("Parse(%q) error = %v, want ErrNoAmount", "USD", err)
+	}
+}
@@ -0,0 +1,28 @@
+package money
+
+import "sort"
+
+// Totals sums amounts per currency
+type Totals map[string]Money
+
+// Add adds m to the total of its currency
+func (t Totals) Add(m Money) {
+	total, ok := t[m.Currency]
+	if !ok {
+		total = Money{Currency: m.Currency}
+	}
+	total.Units += m.Units
+	t[m.Currency] = total
+}
+
+// Sorted returns the totals ordered by currency code
+func (t Totals) Sorted() []Money {
+	totals := make([]Money, 0, len(t))
+	for _, m := range t {
+		totals = append(totals, m)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Currency < totals[j].Currency
+	})
+	return totals
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"isbnAPI/metadata"
+	"net/http"
+	"time"
+)
+
+// newMetadataProvider builds the chain of metadata providers named in the
+// configuration, in order of preference.
+func newMetadataProvider(config Config) (metadata.Provider, error) {
+	client := &http.Client{
+		Timeout: time.Duration(RequestTimeout) * time.Second,
+	}
+	chain := make(metadata.Chain, 0, len(config.MetadataProviders))
+	for _, name := range config.MetadataProviders {
+		switch name {
+		case "isbndb":
+			chain = append(chain, metadata.NewISBNdb(config.ISBNdbBaseURL, client))
+		case "openlibrary":
+			chain = append(chain, metadata.NewOpenLibrary(config.OpenLibraryBaseURL, client))
+		case "googlebooks":
+			chain = append(chain, metadata.NewGoogleBooks(config.GoogleBooksBaseURL, config.GoogleBooksAPIKey, client))
+		default:
+			return nil, fmt.Errorf("unknown metadata provider %q", name)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no metadata provider configured")
+	}
+	return chain, nil
+}
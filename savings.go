@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"isbnAPI/aspen"
+	"isbnAPI/money"
+	"sort"
+)
+
+// saving is the list price of a book and its value in the home currency
+type saving struct {
+	price money.Money
+	home  money.Money
+	// converted is false when the price has no exchange rate to the home currency
+	converted bool
+}
+
+// savingOf returns the saving of a book. It reports false when the book has
+// no usable list price.
+func savingOf(book aspen.Book) (saving, bool) {
+	if book.ListPrice == "" {
+		return saving{}, false
+	}
+	price, err := money.Parse(book.ListPrice)
+	if err != nil {
+		return saving{}, false
+	}
+	s := saving{price: price, home: price, converted: true}
+	if price.Currency != config.HomeCurrency {
+		s.home, s.converted = money.Money{Currency: config.HomeCurrency}, false
+		if rates != nil {
+			converted, err := rates.Convert(price, config.HomeCurrency, book.Checkout.Time)
+			if err != nil {
+				fmt.Printf("Cannot convert the price of %s: %v\n", book.Title, err)
+			} else {
+				s.home, s.converted = converted, true
+			}
+		}
+	}
+	return s, true
+}
+
+// convertSavings sums the list prices of the books in the home currency,
+// converting each price at the rate of the day the book was checked out.
+// It also returns the currencies that could not be converted.
+func convertSavings(books []aspen.Book) (money.Money, []string) {
+	total := money.Money{Currency: config.HomeCurrency}
+	missing := map[string]bool{}
+	for _, book := range books {
+		s, ok := savingOf(book)
+		if !ok {
+			continue
+		}
+		if !s.converted {
+			missing[s.price.Currency] = true
+			continue
+		}
+		total.Units += s.home.Units
+	}
+
+	currencies := make([]string, 0, len(missing))
+	for c := range missing {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	return total, currencies
+}
+
+// finesOf returns the fines of the account. The savings are reported without
+// a deduction when the fines page cannot be read.
+func finesOf(a *Account) []aspen.Fine {
+	fines, err := a.client.Fines(config.HomeCurrency)
+	if err != nil {
+		fmt.Printf("Error reading the fines of %s: %v\n", a.ID, err)
+		return nil
+	}
+	return fines
+}
+
+// convertFines sums the outstanding amounts of the fines in the home
+// currency, as the balance of /fines/ does, converting each at the rate of
+// the day it was charged. Payments and credits are negative and lower the
+// total. It also returns the currencies that could not be converted.
+func convertFines(fines []aspen.Fine) (money.Money, []string) {
+	total := money.Money{Currency: config.HomeCurrency}
+	missing := map[string]bool{}
+	for _, fine := range fines {
+		amount := fine.Outstanding
+		if amount.Currency != config.HomeCurrency {
+			if rates == nil {
+				missing[amount.Currency] = true
+				continue
+			}
+			converted, err := rates.Convert(amount, config.HomeCurrency, fine.Date.Time)
+			if err != nil {
+				fmt.Printf("Cannot convert the fine for %s: %v\n", fine.Title, err)
+				missing[amount.Currency] = true
+				continue
+			}
+			amount = converted
+		}
+		total.Units += amount.Units
+	}
+
+	currencies := make([]string, 0, len(missing))
+	for c := range missing {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	return total, currencies
+}
+
+// netSavings deducts the fines from the savings, both in the home currency
+func netSavings(savings, fines money.Money) money.Money {
+	return money.Money{Units: savings.Units - fines.Units, Currency: config.HomeCurrency}
+}
+
+// savingsGroup sums the savings of the books sharing a key
+type savingsGroup struct {
+	Key string `json:"key"`
+	// Count is the number of books in the group, Priced the number with a list price
+	Count  int `json:"count"`
+	Priced int `json:"priced"`
+	// Totals are the list prices per currency
+	Totals []money.Money `json:"totals"`
+	// Total is the sum in the home currency of the prices that could be converted
+	Total money.Money `json:"total"`
+
+	totals money.Totals
+}
+
+// savingsReport breaks the savings of an account down by checkout date,
+// format and author
+type savingsReport struct {
+	HomeCurrency string        `json:"homeCurrency"`
+	Overall      *savingsGroup `json:"overall"`
+	// Fines is the outstanding balance of the fines of the account and Net the
+	// overall savings less the fines, both in the home currency.
+	// UnconvertedFines lists the currencies of the fines left out of both.
+	Fines            money.Money     `json:"fines"`
+	UnconvertedFines []string        `json:"unconvertedFines"`
+	Net              money.Money     `json:"net"`
+	ByYear           []*savingsGroup `json:"byYear"`
+	ByMonth          []*savingsGroup `json:"byMonth"`
+	ByFormat         []*savingsGroup `json:"byFormat"`
+	ByAuthor         []*savingsGroup `json:"byAuthor"`
+}
+
+// groupBy accumulates savings into groups by key
+type groupBy map[string]*savingsGroup
+
+func (g groupBy) add(key string, s saving, priced bool) {
+	group, ok := g[key]
+	if !ok {
+		group = newSavingsGroup(key)
+		g[key] = group
+	}
+	group.add(s, priced)
+}
+
+func newSavingsGroup(key string) *savingsGroup {
+	return &savingsGroup{
+		Key:    key,
+		Total:  money.Money{Currency: config.HomeCurrency},
+		totals: money.Totals{},
+	}
+}
+
+func (group *savingsGroup) add(s saving, priced bool) {
+	group.Count++
+	if !priced {
+		return
+	}
+	group.Priced++
+	group.totals.Add(s.price)
+	if s.converted {
+		group.Total.Units += s.home.Units
+	}
+}
+
+// byKey returns the groups in key order, for dates
+func (g groupBy) byKey() []*savingsGroup {
+	groups := g.list()
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// byTotal returns the groups with the largest savings first
+func (g groupBy) byTotal() []*savingsGroup {
+	groups := g.list()
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total.Units != groups[j].Total.Units {
+			return groups[i].Total.Units > groups[j].Total.Units
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func (g groupBy) list() []*savingsGroup {
+	groups := make([]*savingsGroup, 0, len(g))
+	for _, group := range g {
+		group.Totals = group.totals.Sorted()
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// buildSavingsReport groups the savings of the books by checkout year and
+// month, by format and by author, and deducts the fines from the overall savings
+func buildSavingsReport(books []aspen.Book, fines []aspen.Fine) savingsReport {
+	overall := newSavingsGroup("all")
+	years, months, formats, authors := groupBy{}, groupBy{}, groupBy{}, groupBy{}
+	for _, book := range books {
+		s, priced := savingOf(book)
+		overall.add(s, priced)
+
+		year, month := "unknown", "unknown"
+		if !book.Checkout.IsZero() {
+			year, month = book.Checkout.Format("2006"), book.Checkout.Format("2006-01")
+		}
+		years.add(year, s, priced)
+		months.add(month, s, priced)
+		formats.add(valueOr(book.Format, "Unknown"), s, priced)
+		authors.add(valueOr(book.Author, "Unknown"), s, priced)
+	}
+	overall.Totals = overall.totals.Sorted()
+	finesTotal, unconvertedFines := convertFines(fines)
+
+	return savingsReport{
+		HomeCurrency:     config.HomeCurrency,
+		Overall:          overall,
+		Fines:            finesTotal,
+		UnconvertedFines: unconvertedFines,
+		Net:              netSavings(overall.Total, finesTotal),
+		ByYear:           years.byKey(),
+		ByMonth:          months.byKey(),
+		ByFormat:         formats.byTotal(),
+		ByAuthor:         authors.byTotal(),
+	}
+}
+
+// valueOr returns s, or fallback when s is empty
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// formatTotal prints a US dollar total as "$12.99" like the former /savings/
+// response, and other currencies as "12.99 EUR"
+func formatTotal(m money.Money) string {
+	if m.Currency == "USD" {
+		return "$" + m.Decimal()
+	}
+	return m.String()
+}
@@ -0,0 +1,200 @@
+// Package store keeps the reading history of the linked library accounts on
+// disk so it does not have to be scraped again on every request.
+package store
+
+import (
+	"encoding/json"
+	"go.etcd.io/bbolt"
+	"isbnAPI/aspen"
+	"sort"
+	"time"
+)
+
+var (
+	booksBucket  = []byte("books")
+	metaBucket   = []byte("meta")
+	syncedKey    = []byte("synced")
+	versionKey   = []byte("version")
+	schemaBucket = []byte("schema")
+)
+
+// schemaVersion is raised when aspen.Book gains fields that older syncs did
+// not store. Opening an older store clears the synced marks so the next sync
+// walks the whole history again.
+const schemaVersion = "2"
+
+// Store is an embedded database of Book records, keyed by account and PermanentId
+type Store struct {
+	db *bbolt.DB
+}
+
+// entry is the stored form of a book. Seq is the order in which the books
+// were stored, used to order books checked out at the same time.
+type entry struct {
+	Seq  uint64     `json:"seq"`
+	Book aspen.Book `json:"book"`
+}
+
+// Open opens the store at path, creating it when it does not exist
+func Open(path string) (*Store, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(migrate); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// migrate clears the synced marks of a store written by an older schema
+func migrate(tx *bbolt.Tx) error {
+	schema, err := tx.CreateBucketIfNotExists(schemaBucket)
+	if err != nil {
+		return err
+	}
+	if string(schema.Get(versionKey)) == schemaVersion {
+		return nil
+	}
+	if meta := tx.Bucket(metaBucket); meta != nil {
+		err := meta.ForEachBucket(func(account []byte) error {
+			return meta.Bucket(account).Delete(syncedKey)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return schema.Put(versionKey, []byte(schemaVersion))
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Book returns the stored book with the given PermanentId
+func (s *Store) Book(account, id string) (aspen.Book, bool, error) {
+	e := entry{}
+	found := false
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := accountBucket(tx, booksBucket, account)
+		if b == nil {
+			return nil
+		}
+		v := b.Get([]byte(id))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &e)
+	})
+	return e.Book, found, err
+}
+
+// Books returns the stored books of the account, most recently checked out first
+func (s *Store) Books(account string) ([]aspen.Book, error) {
+	var entries []entry
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := accountBucket(tx, booksBucket, account)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			e := entry{}
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A book whose record page could not be read is stored by a later sync,
+	// so the order of storage is not the order of the history
+	sort.Slice(entries, func(i, j int) bool {
+		ti, tj := entries[i].Book.LastCheckedOut(), entries[j].Book.LastCheckedOut()
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return entries[i].Seq > entries[j].Seq
+	})
+	books := make([]aspen.Book, len(entries))
+	for i, e := range entries {
+		books[i] = e.Book
+	}
+	return books, nil
+}
+
+// AddBooks stores the books of the account, ordered from the most recently
+// checked out to the oldest. Books already in the store are replaced but
+// keep their Seq.
+func (s *Store) AddBooks(account string, books []aspen.Book) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createAccountBucket(tx, booksBucket, account)
+		if err != nil {
+			return err
+		}
+		base := b.Sequence()
+		next := base + uint64(len(books))
+		for i, book := range books {
+			e := entry{Seq: next - uint64(i), Book: book}
+			if v := b.Get([]byte(book.PermanentId)); v != nil {
+				old := entry{}
+				if err := json.Unmarshal(v, &old); err == nil {
+					e.Seq = old.Seq
+				}
+			}
+			v, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(book.PermanentId), v); err != nil {
+				return err
+			}
+		}
+		return b.SetSequence(next)
+	})
+}
+
+// Synced reports whether a full sync of the account's history has completed
+func (s *Store) Synced(account string) (bool, error) {
+	synced := false
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		b := accountBucket(tx, metaBucket, account)
+		synced = b != nil && b.Get(syncedKey) != nil
+		return nil
+	})
+	return synced, err
+}
+
+// MarkSynced records that the whole history of the account is stored, so
+// later syncs can stop at the first page that holds no new books.
+func (s *Store) MarkSynced(account string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createAccountBucket(tx, metaBucket, account)
+		if err != nil {
+			return err
+		}
+		return b.Put(syncedKey, []byte(time.Now().Format(time.RFC3339)))
+	})
+}
+
+func accountBucket(tx *bbolt.Tx, name []byte, account string) *bbolt.Bucket {
+	root := tx.Bucket(name)
+	if root == nil {
+		return nil
+	}
+	return root.Bucket([]byte(account))
+}
+
+func createAccountBucket(tx *bbolt.Tx, name []byte, account string) (*bbolt.Bucket, error) {
+	root, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return root.CreateBucketIfNotExists([]byte(account))
+}
@@ -1,184 +1,52 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"github.com/PuerkitoBio/goquery"
-	"io"
-	"log"
-	"net/http"
-	"net/url"
-	"regexp"
-	"strconv"
+	"isbnAPI/aspen"
+	"isbnAPI/money"
 	"strings"
 	"sync"
-	"time"
 )
 
-type Result struct {
-	Success                bool   `json:"success"`
-	TwoFactor              bool   `json:"twoFactor"`
-	Name                   string `json:"name"`
-	Phone                  string `json:"phone"`
-	Email                  string `json:"email"`
-	HomeLocation           string `json:"homeLocation"`
-	HomeLocationId         string `json:"homeLocationId"`
-	EnableMaterialsRequest bool   `json:"enableMaterialsRequest"`
-}
-
-type loginResp struct {
-	Result Result `json:"result"`
-}
-
-type Book struct {
-	Author      string `json:"author"`
-	Title       string `json:"title"`
-	Format      string `json:"format"`
-	LinkUrl     string `json:"linkUrl"`
-	PermanentId string `json:"permanentId"`
-	ISBN        string `json:"isbn"`
-	ListPrice   string `json:"list price"`
-}
-
-type history struct {
-	Success bool   `json:"success"`
-	Titles  []Book `json:"titles"`
-}
-
-func login() (bool, string) {
-	postUrl := "https://discovery.roundrocktexas.gov/AJAX/JSON?method=loginUser"
-	data := url.Values{
-		"username": {USERNAME},
-		"password": {PASSWORD},
-	}
-	resp, err := http.PostForm(postUrl, data)
+func login(c *aspen.Client, username, password string) bool {
+	_, err := c.Login(username, password)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	defer resp.Body.Close()
-	loginSuccess := false
-	cookie := ""
-	if resp.StatusCode == http.StatusOK {
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		//bodyString := string(bodyBytes)
-		//fmt.Printf("%v\n", bodyString)
-		respJson := loginResp{}
-		json.Unmarshal(bodyBytes, &respJson)
-		loginSuccess = respJson.Result.Success
-
-		// Print the response cookie
-		cookies := resp.Cookies()
-		for _, c := range cookies {
-			if strings.Contains(c.Name, "aspen_session") {
-				cookie = c.Value
-			}
-		}
+		fmt.Println("Error logging in:", err)
+		return false
 	}
-
-	return loginSuccess, cookie
+	return true
 }
 
-func extractTotalPagination(cookie string) (int, error) {
-
-	// Create a new request
-	req, err := http.NewRequest("GET", "https://discovery.roundrocktexas.gov/MyAccount/AJAX?method=getReadingHistory&patronId="+USERID+"&sort=checkedOut&page=1&readingHistoryFilter=", nil)
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return 0, err
-	}
-	// Add a cookie to the request
-	c := &http.Cookie{
-		Name:  "aspen_session",
-		Value: cookie,
-	}
-	req.AddCookie(c)
-
-	// Perform the request
-	client := &http.Client{
-		Timeout: time.Duration(RequestTimeout) * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("Error performing request:", err)
-		return 0, err
-	}
-	defer resp.Body.Close()
-
-	// Parse the page
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+func extractTotalPagination(c *aspen.Client) (int, error) {
+	total, err := c.TotalPages()
 	if err != nil {
-		fmt.Println("Error parsing the page:", err)
+		fmt.Println("Error reading the pagination:", err)
 		return 0, err
 	}
-
-	// Find the pagination number
-	var pageStr string
-	doc.Find("a[onclick]").Last().Each(func(i int, s *goquery.Selection) {
-		pageStr = s.Text()
-	})
-	re := regexp.MustCompile(`\[(\d+)]`)
-	match := re.FindStringSubmatch(pageStr)
-	if len(match) > 1 {
-		number, _ := strconv.Atoi(match[1])
-		return number, nil
-	} else {
-		return 1, nil
-	}
+	return total, nil
 }
 
-func readBookHistoryList(cookie string, paginationTotal int) []Book {
-	urls := make([]string, paginationTotal+1)
-	for i := 1; i < len(urls); i++ {
-		urls[i] = fmt.Sprintf("https://discovery.roundrocktexas.gov/MyAccount/AJAX?method=getReadingHistory&patronId=%s&sort=checkedOut&page=%d&readingHistoryFilter=", USERID, i)
+func readBookHistoryList(c *aspen.Client) []aspen.Book {
+	// Count the pages on every fetch since new checkouts can add a page
+	paginationTotal, err := extractTotalPagination(c)
+	if err != nil {
+		return nil
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(len(urls))
-	bookChan := make(chan history)
-
-	for _, u := range urls {
-		go func(uri string, resCan chan history) {
-			respJson := history{}
+	wg.Add(paginationTotal)
+	bookChan := make(chan aspen.History)
 
+	for page := 1; page <= paginationTotal; page++ {
+		go func(page int, resCan chan aspen.History) {
 			defer wg.Done()
-			// Create a new request
-			req, err := http.NewRequest("GET", uri, nil)
-			if err != nil {
-				fmt.Println("Error creating request:", err)
-				return
-			}
-			// Add a cookie to the request
-			c := &http.Cookie{
-				Name:  "aspen_session",
-				Value: cookie,
-			}
-			req.AddCookie(c)
-
-			// Perform the request
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				fmt.Println("Error performing request:", err)
-				return
-			}
-			defer resp.Body.Close()
-
-			// Read the response
-			bodyBytes, err := io.ReadAll(resp.Body)
+			respJson, err := c.ReadingHistoryPage(page)
 			if err != nil {
-				fmt.Println("Error reading response:", err)
+				fmt.Println("Error reading history page:", err)
 				return
 			}
-
-			json.Unmarshal(bodyBytes, &respJson)
-
 			resCan <- respJson
-		}(u, bookChan)
+		}(page, bookChan)
 	}
 
 	go func() {
@@ -187,7 +55,7 @@ func readBookHistoryList(cookie string, paginationTotal int) []Book {
 	}()
 
 	// Collect the list of books in the checkout pages
-	checkedOutBooks := make([]Book, 0)
+	checkedOutBooks := make([]aspen.Book, 0)
 	for res := range bookChan {
 		if res.Success {
 			for _, t := range res.Titles {
@@ -197,6 +65,14 @@ func readBookHistoryList(cookie string, paginationTotal int) []Book {
 
 	}
 
+	books, _ := addBookDetails(c, checkedOutBooks)
+	return books
+}
+
+// addBookDetails fills in the ISBN and list price of the books from their
+// record pages. It also returns the ids of the books whose record page could
+// be read.
+func addBookDetails(c *aspen.Client, checkedOutBooks []aspen.Book) ([]aspen.Book, map[string]bool) {
 	// Get the ISBN by checking the detail page of the book
 	bookId2ISBNnPrice := make(map[string]map[string]string)
 	var wg2 sync.WaitGroup
@@ -205,7 +81,7 @@ func readBookHistoryList(cookie string, paginationTotal int) []Book {
 	for _, book := range checkedOutBooks {
 		wg2.Add(1)
 		// Spin up goroutines to extract isbn
-		go extractISBNAndListPrice(book, cookie, isbnPriceChan, &wg2)
+		go extractISBNAndListPrice(book, c, isbnPriceChan, &wg2)
 	}
 	go func() {
 		wg2.Wait()
@@ -217,6 +93,9 @@ func readBookHistoryList(cookie string, paginationTotal int) []Book {
 	recvdBooks := 0
 	for isbnNPrice := range isbnPriceChan {
 		recvdBooks += 1
+		if isbnNPrice == nil {
+			continue
+		}
 		bookId2ISBNnPrice[isbnNPrice["id"]] = make(map[string]string)
 		bookId2ISBNnPrice[isbnNPrice["id"]]["ISBN"] = isbnNPrice["isbn"]
 		bookId2ISBNnPrice[isbnNPrice["id"]]["list price"] = isbnNPrice["list price"]
@@ -226,8 +105,10 @@ func readBookHistoryList(cookie string, paginationTotal int) []Book {
 	//fmt.Printf("bookId2 ISBN and Price: %v\n", bookId2ISBNnPrice)
 
 	// Add the ISBN to the book
-	finalCheckedOutBooks := make([]Book, len(checkedOutBooks))
+	fetched := make(map[string]bool)
+	finalCheckedOutBooks := make([]aspen.Book, len(checkedOutBooks))
 	for i, book := range checkedOutBooks {
+		_, fetched[book.PermanentId] = bookId2ISBNnPrice[book.PermanentId]
 		book.ISBN = bookId2ISBNnPrice[book.PermanentId]["ISBN"]
 		book.ListPrice = bookId2ISBNnPrice[book.PermanentId]["list price"]
 		if book.Title == "" {
@@ -235,74 +116,33 @@ func readBookHistoryList(cookie string, paginationTotal int) []Book {
 		}
 		finalCheckedOutBooks[i] = book
 	}
-	return finalCheckedOutBooks
+	return finalCheckedOutBooks, fetched
 }
 
-func extractISBNAndListPrice(book Book, cookie string, resChan chan<- map[string]string, wg *sync.WaitGroup) {
+func extractISBNAndListPrice(book aspen.Book, c *aspen.Client, resChan chan<- map[string]string, wg *sync.WaitGroup) {
 	defer wg.Done()
-	u := "https://discovery.roundrocktexas.gov" + book.LinkUrl
-	// Create a new request
-	req, err := http.NewRequest("GET", u, nil)
+	// Get the ISBN on the detail page of the book
+	record, err := c.Record(book.LinkUrl)
 	if err != nil {
-		fmt.Println("Error creating request:", err)
-		resChan <- nil
-	}
-	// Add a cookie to the request
-	c := &http.Cookie{
-		Name:  "aspen_session",
-		Value: cookie,
-	}
-	req.AddCookie(c)
-
-	// Perform the request to get the ISBN on the detail page of the book
-	client := &http.Client{
-		Timeout: time.Duration(RequestTimeout) * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("Error performing request:", err)
+		fmt.Println("Error reading record page:", err)
 		resChan <- nil
 		return
 	}
-	defer resp.Body.Close()
-
-	// Parse the page
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		fmt.Println("Error parsing the page:", err)
-		resChan <- nil
-		return
-	}
-
-	// Find the div with class "result-label" containing the ISBN
-	isbnDiv := doc.Find("div.result-label:contains('ISBN')")
-
-	// Initialize a slice to store individual ISBNs
-	var isbnList []string
-	// Iterate over child nodes and extract text
-	isbnDiv.Next().Contents().Each(func(i int, s *goquery.Selection) {
-		// Split the text based on line breaks
-		isbns := strings.Split(strings.TrimSpace(s.Text()), "<br/>")
-		for _, isbn := range isbns {
-			if isbn != "" {
-				isbnList = append(isbnList, isbn)
-			}
-		}
-	})
-
-	// Extract the ISBN value from the sibling div
-	//isbnText := isbnDiv.Next().Text()
-	// Split the ISBNs based on line breaks <br/>
+	isbnList := validISBNs(record.ISBNs)
 	isbn := strings.Join(isbnList, ",")
 
 	content := map[string]string{}
 	content["id"] = book.PermanentId
 	content["isbn"] = isbn
-	// Get the list price of the book from the ISBN page
+	// Get the list price of the book, trying each edition until one has a price
 	if len(isbnList) > 0 {
-		isbnContent := ISBNContent(isbnList[0])
-		content["list price"] = isbnContent["list price"]
-		content["title"] = isbnContent["full title"]
+		content["list price"] = ""
+		content["title"] = book.Title
+		if m, ok := priceRecord(isbnList, record.Qualifiers); ok {
+			isbnContent := m.Legacy()
+			content["list price"] = isbnContent["list price"]
+			content["title"] = isbnContent["full title"]
+		}
 	} else {
 		fmt.Printf("No ISBN found for %s\n", book.Title)
 		content["list price"] = ""
@@ -312,74 +152,28 @@ func extractISBNAndListPrice(book Book, cookie string, resChan chan<- map[string
 	resChan <- content
 }
 
-func calculateTotalSavings(cookie string, totalPagination int) float64 {
-	totalSavedAmnt := 0.0
-	books := readBookHistoryList(cookie, totalPagination)
-	for _, book := range books {
-		if book.ListPrice != "" {
-			price := book.ListPrice
-			// Define a regular expression to match the pattern "USD $X.X"
-			re := regexp.MustCompile(`USD \$([\d.]+)`)
-			// Find the match in the input string
-			match := re.FindStringSubmatch(price)
-			// Extract and convert the matched price to a float64
-			p, _ := strconv.ParseFloat(match[1], 64)
-			totalSavedAmnt += p
-		}
-	}
-	return totalSavedAmnt
-}
-
 // For worker pool implementation
 
-func readBookHistoryList2(cookie string, paginationTotal int) []Book {
-	urls := make([]string, paginationTotal+1)
-	for i := 1; i < len(urls); i++ {
-		urls[i] = fmt.Sprintf("https://discovery.roundrocktexas.gov/MyAccount/AJAX?method=getReadingHistory&patronId=%s&sort=checkedOut&page=%d&readingHistoryFilter=", USERID, i)
+func readBookHistoryList2(c *aspen.Client) []aspen.Book {
+	paginationTotal, err := extractTotalPagination(c)
+	if err != nil {
+		return nil
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(len(urls))
-	bookChan := make(chan history)
-
-	for _, u := range urls {
-		go func(uri string, resCan chan history) {
-			respJson := history{}
+	wg.Add(paginationTotal)
+	bookChan := make(chan aspen.History)
 
+	for page := 1; page <= paginationTotal; page++ {
+		go func(page int, resCan chan aspen.History) {
 			defer wg.Done()
-			// Create a new request
-			req, err := http.NewRequest("GET", uri, nil)
-			if err != nil {
-				fmt.Println("Error creating request:", err)
-				return
-			}
-			// Add a cookie to the request
-			c := &http.Cookie{
-				Name:  "aspen_session",
-				Value: cookie,
-			}
-			req.AddCookie(c)
-
-			// Perform the request
-			client := &http.Client{}
-			resp, err := client.Do(req)
+			respJson, err := c.ReadingHistoryPage(page)
 			if err != nil {
-				fmt.Println("Error performing request:", err)
+				fmt.Println("Error reading history page:", err)
 				return
 			}
-			defer resp.Body.Close()
-
-			// Read the response
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				fmt.Println("Error reading response:", err)
-				return
-			}
-
-			json.Unmarshal(bodyBytes, &respJson)
-
 			resCan <- respJson
-		}(u, bookChan)
+		}(page, bookChan)
 	}
 
 	go func() {
@@ -388,7 +182,7 @@ func readBookHistoryList2(cookie string, paginationTotal int) []Book {
 	}()
 
 	// Collect the list of books in the checkout pages
-	checkedOutBooks := make([]Book, 0)
+	checkedOutBooks := make([]aspen.Book, 0)
 	for res := range bookChan {
 		if res.Success {
 			for _, t := range res.Titles {
@@ -401,164 +195,59 @@ func readBookHistoryList2(cookie string, paginationTotal int) []Book {
 }
 
 func extractISBNAndListPrice2(args []interface{}) (interface{}, error) {
-	book := args[0].(*Book)
-	cookie := args[1].(string)
+	book := args[0].(*aspen.Book)
+	c := args[1].(*aspen.Client)
 
 	result := map[string]string{}
-	// Create a new request to get the book details page
-	u := "https://discovery.roundrocktexas.gov" + book.LinkUrl
-	req, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return nil, err
-	}
-	c := &http.Cookie{
-		Name:  "aspen_session",
-		Value: cookie,
-	}
-	req.AddCookie(c)
-
-	// Perform the request
-	client := &http.Client{
-		Timeout: time.Duration(RequestTimeout) * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("Error performing request:", err)
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	// Parse the page to get the ISBN
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	// Get the ISBN on the detail page of the book
+	record, err := c.Record(book.LinkUrl)
 	if err != nil {
-		fmt.Println("Error parsing the page:", err)
+		fmt.Println("Error reading record page:", err)
 		return nil, err
 	}
-	// Find the div with class "result-label" containing the ISBN
-	isbnDiv := doc.Find("div.result-label:contains('ISBN')")
-	// Initialize a slice to store individual ISBNs
-	var isbnList []string
-	// Iterate over child nodes and extract text
-	isbnDiv.Next().Contents().Each(func(i int, s *goquery.Selection) {
-		// Split the text based on line breaks
-		isbns := strings.Split(strings.TrimSpace(s.Text()), "<br/>")
-		for _, isbn := range isbns {
-			if isbn != "" {
-				isbnList = append(isbnList, isbn)
-			}
-		}
-	})
-
-	// Extract the ISBN value from the sibling div
+	isbnList := validISBNs(record.ISBNs)
 	isbn := strings.Join(isbnList, ",")
 	book.ISBN = isbn
-	if len(isbnList) > 0 {
-		isbnContent := ISBNContent(isbnList[0])
+	if m, ok := priceRecord(isbnList, record.Qualifiers); ok {
+		isbnContent := m.Legacy()
 		book.ListPrice = isbnContent["list price"]
 		book.Title = isbnContent["full title"]
 	}
 	return result, nil
 }
 
-func calculateTotalSavings2(books []Book) float64 {
-	totalSavedAmnt := 0.0
+// calculateTotalSavings2 sums the list prices of the books per currency
+func calculateTotalSavings2(books []aspen.Book) money.Totals {
+	totals := money.Totals{}
 	for _, book := range books {
 		if book.ListPrice != "" {
-			price := book.ListPrice
-			// Define a regular expression to match the pattern "USD $X.X"
-			re := regexp.MustCompile(`USD \$([\d.]+)`)
-			// Find the match in the input string
-			match := re.FindStringSubmatch(price)
-			// Extract and convert the matched price to a float64
-			p, _ := strconv.ParseFloat(match[1], 64)
-			totalSavedAmnt += p
+			price, err := money.Parse(book.ListPrice)
+			if err != nil {
+				fmt.Printf("Skipping price %q of %s: %v\n", book.ListPrice, book.Title, err)
+				continue
+			}
+			totals.Add(price)
 		}
 	}
-	return totalSavedAmnt
+	return totals
 }
 
-func checkedOutBooks(cookie string) ([]map[string]string, error) {
-	u := "https://discovery.roundrocktexas.gov/MyAccount/AJAX?method=getCheckouts&source=all"
-	// Create a new request
-	req, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return nil, err
-	}
-	// Add a cookie to the request
-	c := &http.Cookie{
-		Name:  "aspen_session",
-		Value: cookie,
-	}
-	req.AddCookie(c)
-
-	// Perform the request to get the ISBN on the detail page of the book
-	client := &http.Client{
-		Timeout: time.Duration(RequestTimeout) * time.Second,
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Println("Error performing request:", err)
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	// Parse the page
-	fmt.Println("Response status:", resp.Status)
-	// Read the response
-	bodyBytes, err := io.ReadAll(resp.Body)
+// checkedOutBooks returns the checkouts of the patron once each. Copies and
+// formats of the same title are kept apart by their checkout id.
+func checkedOutBooks(c *aspen.Client) ([]aspen.Checkout, error) {
+	checkouts, err := c.Checkouts()
 	if err != nil {
-		fmt.Println("Error reading response:", err)
+		fmt.Println("Error reading checkouts:", err)
 		return nil, err
 	}
-	//fmt.Println(string(bodyBytes))
 
-	var respJson map[string]interface{}
-	json.Unmarshal(bodyBytes, &respJson)
-	checkOuts := respJson["checkouts"].(interface{})
-	checkBookStr := checkOuts.(string)
-	//fmt.Printf("checkBookStr: %s\n", checkBookStr)
-
-	// Create a new bytes.Reader from the checkBookStr
-	b := strings.NewReader(checkBookStr)
-	doc, err := goquery.NewDocumentFromReader(b)
-	if err != nil {
-		fmt.Println("Error parsing the page:", err)
-		return nil, err
-	}
-
-	// Use CSS selectors to find the book names and due dates
-	var dueBooks []map[string]string
-	var bookTitles []string
-	doc.Find(".result.row").Each(func(i int, s *goquery.Selection) {
-		// Extract book name
-		bookName := s.Find(".result-title").Text()
-
-		// Extract due date
-		dueDate := s.Find(".result-label:contains('Due') + .result-value").Text()
-
-		// Print the results
-
-		found := contains(bookTitles, bookName)
-		if !found {
-			book := map[string]string{}
-			book["title"] = bookName
-			book["due date"] = dueDate
-			dueBooks = append(dueBooks, book)
-			bookTitles = append(bookTitles, bookName)
-		}
-
-	})
-
-	return dueBooks, nil
-}
-
-func contains(arr []string, target string) bool {
-	for _, num := range arr {
-		if num == target {
-			return true
+	dueBooks := make([]aspen.Checkout, 0, len(checkouts))
+	seen := make(map[string]bool)
+	for _, checkout := range checkouts {
+		if !seen[checkout.ID] {
+			dueBooks = append(dueBooks, checkout)
+			seen[checkout.ID] = true
 		}
 	}
-	return false
+	return dueBooks, nil
 }